@@ -0,0 +1,286 @@
+package main
+
+import (
+	"bytes"
+	"encoding/json"
+	"errors"
+	"flag"
+	"fmt"
+	"io"
+	"io/ioutil"
+	"net/http"
+	"os"
+	"path/filepath"
+	"strings"
+
+	"gopkg.in/yaml.v3"
+
+	"github.com/sgrodriguez/ddt"
+	"github.com/sgrodriguez/ddt/function"
+	"github.com/sgrodriguez/ddt/server"
+)
+
+// Tree file formats
+const (
+	formatJSON       = "json"
+	formatNestedJSON = "nested-json"
+	formatYAML       = "yaml"
+	formatNestedYAML = "nested-yaml"
+)
+
+func validateCmd(e *env, args []string) error {
+	fs := flag.NewFlagSet("validate", flag.ContinueOnError)
+	if err := parseFlags(e, fs, args, 1, "TREE..."); err != nil {
+		return err
+	}
+	failed := 0
+	for _, path := range fs.Args() {
+		if _, _, err := readTree(path); err != nil {
+			fmt.Fprintf(e.stdout, "%s: %v\n", path, err)
+			failed++
+			continue
+		}
+		fmt.Fprintf(e.stdout, "%s: ok\n", path)
+	}
+	if failed != 0 {
+		return fmt.Errorf("%d invalid tree files", failed)
+	}
+	return nil
+}
+
+func fmtCmd(e *env, args []string) error {
+	fs := flag.NewFlagSet("fmt", flag.ContinueOnError)
+	if err := parseFlags(e, fs, args, 1, "TREE"); err != nil {
+		return err
+	}
+	tree, format, err := readTree(fs.Arg(0))
+	if err != nil {
+		return err
+	}
+	return writeTree(e.stdout, tree, format)
+}
+
+func convertCmd(e *env, args []string) error {
+	fs := flag.NewFlagSet("convert", flag.ContinueOnError)
+	to := fs.String("to", formatJSON, "output format: json, nested-json, yaml or nested-yaml")
+	if err := parseFlags(e, fs, args, 1, "TREE"); err != nil {
+		return err
+	}
+	tree, _, err := readTree(fs.Arg(0))
+	if err != nil {
+		return err
+	}
+	return writeTree(e.stdout, tree, *to)
+}
+
+func renderCmd(e *env, args []string) error {
+	fs := flag.NewFlagSet("render", flag.ContinueOnError)
+	format := fs.String("format", "dot", "output format: dot or mermaid")
+	input := fs.String("input", "", "json input file whose resolution path is highlighted")
+	if err := parseFlags(e, fs, args, 1, "TREE"); err != nil {
+		return err
+	}
+	tree, _, err := readTree(fs.Arg(0))
+	if err != nil {
+		return err
+	}
+	var path []int
+	// a failed resolution still highlights the path followed until the failure
+	var resolveErr error
+	if *input != "" {
+		inputs, err := readInputs(e, *input)
+		if err != nil {
+			return err
+		}
+		if len(inputs) == 0 {
+			return errors.New("empty input file")
+		}
+		var trace *ddt.Trace
+		_, trace, resolveErr = ddt.ExplainTree(tree, inputs[0])
+		path = trace.VisitedIDs()
+	}
+	switch *format {
+	case "dot":
+		err = ddt.WriteDOT(e.stdout, tree, path...)
+	case "mermaid":
+		err = ddt.WriteMermaid(e.stdout, tree, path...)
+	default:
+		return fmt.Errorf("unknown render format %q", *format)
+	}
+	if err != nil {
+		return err
+	}
+	if resolveErr != nil {
+		return fmt.Errorf("resolving input: %w", resolveErr)
+	}
+	return nil
+}
+
+// resolution is printed for each input of the resolve command
+type resolution struct {
+	Result interface{} `json:"result"`
+	Trace  *ddt.Trace  `json:"trace,omitempty"`
+	Error  string      `json:"error,omitempty"`
+}
+
+func resolveCmd(e *env, args []string) error {
+	fs := flag.NewFlagSet("resolve", flag.ContinueOnError)
+	inputsPath := fs.String("inputs", "-", "file with the json inputs, - reads stdin")
+	withTrace := fs.Bool("trace", false, "print the trace of each resolution")
+	if err := parseFlags(e, fs, args, 1, "TREE"); err != nil {
+		return err
+	}
+	tree, _, err := readTree(fs.Arg(0))
+	if err != nil {
+		return err
+	}
+	inputs, err := readInputs(e, *inputsPath)
+	if err != nil {
+		return err
+	}
+	enc := json.NewEncoder(e.stdout)
+	failed := 0
+	for _, input := range inputs {
+		result, trace, err := ddt.ExplainTree(tree, input)
+		res := resolution{Result: result}
+		if *withTrace {
+			res.Trace = trace
+		}
+		if err != nil {
+			res.Error = err.Error()
+			failed++
+		}
+		if err := enc.Encode(res); err != nil {
+			return err
+		}
+	}
+	if failed != 0 {
+		return fmt.Errorf("%d of %d inputs failed", failed, len(inputs))
+	}
+	return nil
+}
+
+func serveCmd(e *env, args []string) error {
+	fs := flag.NewFlagSet("serve", flag.ContinueOnError)
+	addr := fs.String("addr", ":8080", "address to listen on")
+	if err := parseFlags(e, fs, args, 1, "TREE..."); err != nil {
+		return err
+	}
+	srv, err := newServer(fs.Args())
+	if err != nil {
+		return err
+	}
+	fmt.Fprintf(e.stderr, "serving %d trees on %s\n", len(fs.Args()), *addr)
+	return http.ListenAndServe(*addr, srv)
+}
+
+// newServer creates a server for the tree files, trees without name are
+// served by the file name without extension
+func newServer(paths []string) (*server.Server, error) {
+	srv, err := server.New()
+	if err != nil {
+		return nil, err
+	}
+	for _, path := range paths {
+		tree, _, err := readTree(path)
+		if err != nil {
+			return nil, fmt.Errorf("%s: %w", path, err)
+		}
+		if tree.Name == "" {
+			tree.Name = strings.TrimSuffix(filepath.Base(path), filepath.Ext(path))
+		}
+		if _, ok := srv.Holder(tree.Name); ok {
+			return nil, fmt.Errorf("%s: duplicate tree name %q", path, tree.Name)
+		}
+		if err := srv.Add(tree); err != nil {
+			return nil, fmt.Errorf("%s: %w", path, err)
+		}
+	}
+	return srv, nil
+}
+
+// readTree decodes a tree file returning the format it is written in
+func readTree(path string) (*ddt.Tree, string, error) {
+	data, err := ioutil.ReadFile(path)
+	if err != nil {
+		return nil, "", err
+	}
+	tree := &ddt.Tree{}
+	var nested struct {
+		Root interface{} `json:"root" yaml:"root"`
+	}
+	if ext := filepath.Ext(path); ext == ".yaml" || ext == ".yml" {
+		if err := yaml.Unmarshal(data, tree); err != nil {
+			return nil, "", err
+		}
+		_ = yaml.Unmarshal(data, &nested)
+		if nested.Root != nil {
+			return tree, formatNestedYAML, nil
+		}
+		return tree, formatYAML, nil
+	}
+	if err := json.Unmarshal(data, tree); err != nil {
+		return nil, "", err
+	}
+	_ = json.Unmarshal(data, &nested)
+	if nested.Root != nil {
+		return tree, formatNestedJSON, nil
+	}
+	return tree, formatJSON, nil
+}
+
+func writeTree(w io.Writer, tree *ddt.Tree, format string) error {
+	var b []byte
+	var err error
+	switch format {
+	case formatJSON:
+		b, err = json.Marshal(tree)
+	case formatNestedJSON:
+		b, err = tree.MarshalNestedJSON()
+	case formatYAML:
+		b, err = yaml.Marshal(tree)
+	case formatNestedYAML:
+		b, err = tree.MarshalNestedYAML()
+	default:
+		return fmt.Errorf("unknown tree format %q", format)
+	}
+	if err != nil {
+		return err
+	}
+	if format == formatJSON || format == formatNestedJSON {
+		var indented bytes.Buffer
+		if err := json.Indent(&indented, b, "", "  "); err != nil {
+			return err
+		}
+		b = append(indented.Bytes(), '\n')
+	}
+	_, err = w.Write(b)
+	return err
+}
+
+// readInputs decodes the stream of json documents of the file, or stdin for -
+func readInputs(e *env, path string) ([]interface{}, error) {
+	r := e.stdin
+	if path != "-" {
+		f, err := os.Open(path)
+		if err != nil {
+			return nil, err
+		}
+		defer f.Close()
+		r = f
+	}
+	dec := json.NewDecoder(r)
+	dec.UseNumber()
+	var inputs []interface{}
+	for {
+		var input interface{}
+		err := dec.Decode(&input)
+		if err == io.EOF {
+			return inputs, nil
+		}
+		if err != nil {
+			return nil, err
+		}
+		inputs = append(inputs, function.NormalizeJSON(input))
+	}
+}
@@ -0,0 +1,99 @@
+// Command ddt validates, formats, converts, renders and resolves decision
+// trees defined in json (flat or nested) or yaml files.
+//
+// Usage:
+//
+//	ddt validate TREE...
+//	ddt fmt TREE
+//	ddt convert -to json|nested-json|yaml|nested-yaml TREE
+//	ddt render [-format dot|mermaid] [-input FILE] TREE
+//	ddt resolve [-inputs FILE] [-trace] TREE
+//	ddt serve [-addr ADDR] TREE...
+//
+// Files with the .yaml or .yml extension are read as yaml, any other as json.
+// The inputs of resolve are json documents read from the inputs file or
+// stdin, one result is printed per input as a json line. serve exposes the
+// trees through the HTTP API of the server package.
+package main
+
+import (
+	"errors"
+	"flag"
+	"fmt"
+	"io"
+	"os"
+)
+
+const usage = `usage: ddt <command> [flags] TREE
+
+commands:
+  validate  validate one or more tree files
+  fmt       pretty-print a tree file keeping its format
+  convert   convert a tree file to json, nested-json, yaml or nested-yaml
+  render    render a tree file to Graphviz DOT or Mermaid
+  resolve   resolve a tree file against json inputs read from a file or stdin
+  serve     serve tree files through a json HTTP API
+`
+
+// errUsage is returned by the commands invoked with invalid flags or args
+var errUsage = errors.New("invalid usage")
+
+// env holds the standard streams of the command
+type env struct {
+	stdin  io.Reader
+	stdout io.Writer
+	stderr io.Writer
+}
+
+type command func(e *env, args []string) error
+
+func main() {
+	os.Exit(run(os.Args[1:], os.Stdin, os.Stdout, os.Stderr))
+}
+
+// run executes the command given by args returning the exit code
+func run(args []string, stdin io.Reader, stdout, stderr io.Writer) int {
+	if len(args) == 0 {
+		fmt.Fprint(stderr, usage)
+		return 2
+	}
+	commands := map[string]command{
+		"validate": validateCmd,
+		"fmt":      fmtCmd,
+		"convert":  convertCmd,
+		"render":   renderCmd,
+		"resolve":  resolveCmd,
+		"serve":    serveCmd,
+	}
+	cmd, ok := commands[args[0]]
+	if !ok {
+		fmt.Fprintf(stderr, "ddt: unknown command %q\n%s", args[0], usage)
+		return 2
+	}
+	err := cmd(&env{stdin: stdin, stdout: stdout, stderr: stderr}, args[1:])
+	switch {
+	case err == nil:
+		return 0
+	case errors.Is(err, errUsage):
+		return 2
+	}
+	fmt.Fprintf(stderr, "ddt %s: %v\n", args[0], err)
+	return 1
+}
+
+// parseFlags parses the flags of a command requiring at least minArgs args
+func parseFlags(e *env, fs *flag.FlagSet, args []string, minArgs int, argsUsage string) error {
+	fs.SetOutput(e.stderr)
+	fs.Usage = func() {
+		fmt.Fprintf(e.stderr, "usage: ddt %s [flags] %s\n", fs.Name(), argsUsage)
+		fs.PrintDefaults()
+	}
+	if err := fs.Parse(args); err != nil {
+		return errUsage
+	}
+	if fs.NArg() < minArgs {
+		fs.Usage()
+		return errUsage
+	}
+	return nil
+}
@@ -0,0 +1,155 @@
+package main
+
+import (
+	"bytes"
+	"encoding/json"
+	"io/ioutil"
+	"path/filepath"
+	"strings"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"github.com/sgrodriguez/ddt/server"
+)
+
+func runCmd(stdin string, args ...string) (int, string, string) {
+	var stdout, stderr bytes.Buffer
+	code := run(args, strings.NewReader(stdin), &stdout, &stderr)
+	return code, stdout.String(), stderr.String()
+}
+
+func TestValidate(t *testing.T) {
+	code, stdout, _ := runCmd("", "validate", "testdata/simple_tree.json", "testdata/simple_tree.yaml")
+	assert.Equal(t, 0, code)
+	assert.Equal(t, "testdata/simple_tree.json: ok\ntestdata/simple_tree.yaml: ok\n", stdout)
+
+	code, stdout, stderr := runCmd("", "validate", "testdata/simple_tree.json", "testdata/orphans.json")
+	assert.Equal(t, 1, code)
+	assert.Contains(t, stdout, "testdata/orphans.json: orphan nodes not reachable from root: [3]\n")
+	assert.Equal(t, "ddt validate: 1 invalid tree files\n", stderr)
+}
+
+func TestUsage(t *testing.T) {
+	code, _, stderr := runCmd("")
+	assert.Equal(t, 2, code)
+	assert.Contains(t, stderr, "usage: ddt <command>")
+	code, _, stderr = runCmd("", "unknown")
+	assert.Equal(t, 2, code)
+	assert.Contains(t, stderr, `unknown command "unknown"`)
+	code, _, stderr = runCmd("", "resolve")
+	assert.Equal(t, 2, code)
+	assert.Contains(t, stderr, "usage: ddt resolve [flags] TREE")
+	code, _, _ = runCmd("", "convert", "-unknown", "testdata/simple_tree.json")
+	assert.Equal(t, 2, code)
+}
+
+func TestFmtAndConvert(t *testing.T) {
+	code, stdout, _ := runCmd("", "fmt", "testdata/simple_tree.yaml")
+	require.Equal(t, 0, code)
+	assert.True(t, strings.HasPrefix(stdout, "name: simpleTree\nroot:\n"))
+
+	code, flat, _ := runCmd("", "convert", "-to", "json", "testdata/simple_tree.yaml")
+	require.Equal(t, 0, code)
+	code, expected, _ := runCmd("", "convert", "-to", "json", "testdata/simple_tree.json")
+	require.Equal(t, 0, code)
+	assert.JSONEq(t, expected, flat)
+
+	// every format converts back to the same tree
+	dir := t.TempDir()
+	for format, ext := range map[string]string{"json": ".json", "nested-json": ".json", "yaml": ".yaml", "nested-yaml": ".yaml"} {
+		code, converted, stderr := runCmd("", "convert", "-to", format, "testdata/simple_tree.json")
+		require.Equal(t, 0, code, stderr)
+		path := filepath.Join(dir, format+ext)
+		require.NoError(t, ioutil.WriteFile(path, []byte(converted), 0600))
+		code, back, _ := runCmd("", "convert", path)
+		require.Equal(t, 0, code)
+		assert.JSONEq(t, flat, back, format)
+		code, formatted, _ := runCmd("", "fmt", path)
+		require.Equal(t, 0, code)
+		assert.Equal(t, converted, formatted, format)
+	}
+
+	code, _, stderr := runCmd("", "convert", "-to", "xml", "testdata/simple_tree.json")
+	assert.Equal(t, 1, code)
+	assert.Equal(t, "ddt convert: unknown tree format \"xml\"\n", stderr)
+}
+
+func TestRender(t *testing.T) {
+	code, stdout, _ := runCmd("", "render", "-input", "testdata/inputs.json", "testdata/simple_tree.json")
+	require.Equal(t, 0, code)
+	assert.Contains(t, stdout, `digraph "simpleTree" {`)
+	assert.Contains(t, stdout, `n0 -> n1 [label="> 60", color=red, penwidth=2];`)
+	code, stdout, _ = runCmd("", "render", "-format", "mermaid", "testdata/simple_tree.yaml")
+	require.Equal(t, 0, code)
+	assert.Contains(t, stdout, "flowchart TD\n")
+	code, _, _ = runCmd("", "render", "-format", "png", "testdata/simple_tree.yaml")
+	assert.Equal(t, 1, code)
+
+	code, stdout, stderr := runCmd(`"invalid"`, "render", "-input", "-", "testdata/simple_tree.json")
+	assert.Equal(t, 1, code)
+	assert.Contains(t, stdout, `n0 [label="#0 input", shape=ellipse, color=red, penwidth=2];`)
+	assert.Equal(t, "ddt render: resolving input: node 0 (depth 0): value not found when comparing with all children nodes\n", stderr)
+}
+
+func TestResolve(t *testing.T) {
+	code, stdout, stderr := runCmd("", "resolve", "-inputs", "testdata/inputs.json", "testdata/simple_tree.json")
+	assert.Equal(t, 1, code)
+	assert.Equal(t, `{"result":"prize1"}
+{"result":"prize2"}
+{"result":"prize3"}
+{"result":"prize4"}
+{"result":null,"error":"node 0 (depth 0): value not found when comparing with all children nodes"}
+`, stdout)
+	assert.Equal(t, "ddt resolve: 1 of 5 inputs failed\n", stderr)
+
+	code, stdout, _ = runCmd("45", "resolve", "-trace", "testdata/simple_tree.yaml")
+	require.Equal(t, 0, code)
+	res := struct {
+		Result string
+		Trace  struct {
+			Steps []struct {
+				NodeID int
+			}
+		}
+	}{}
+	require.NoError(t, json.Unmarshal([]byte(stdout), &res))
+	assert.Equal(t, "prize3", res.Result)
+	assert.Len(t, res.Trace.Steps, 3)
+
+	code, _, stderr = runCmd("{", "resolve", "testdata/simple_tree.yaml")
+	assert.Equal(t, 1, code)
+	assert.Equal(t, "ddt resolve: unexpected EOF\n", stderr)
+}
+
+func TestReadInputs(t *testing.T) {
+	docs := []string{`1`, `1.5`, `9007199254740993`, `1e3`, `{"a": [2, 2.5, {"b": 92233720368547758070}]}`}
+	inputs, err := readInputs(&env{stdin: strings.NewReader(strings.Join(docs, "\n"))}, "-")
+	require.NoError(t, err)
+	require.Len(t, inputs, len(docs))
+	// the inputs are decoded like the server does
+	for i, doc := range docs {
+		expected, err := server.DecodeInput([]byte(doc))
+		require.NoError(t, err)
+		assert.Equal(t, expected, inputs[i], doc)
+	}
+	assert.Equal(t, 9007199254740993, inputs[2])
+}
+
+func TestNewServer(t *testing.T) {
+	dir := t.TempDir()
+	unnamed := filepath.Join(dir, "unnamed.yaml")
+	require.NoError(t, ioutil.WriteFile(unnamed, []byte("root:\n  children:\n    - {default: true, result: {Value: ok, Type: string}}\n"), 0600))
+	srv, err := newServer([]string{"testdata/simple_tree.json", unnamed})
+	require.NoError(t, err)
+	_, ok := srv.Holder("simpleTree")
+	assert.True(t, ok)
+	_, ok = srv.Holder("unnamed")
+	assert.True(t, ok)
+
+	_, err = newServer([]string{"testdata/simple_tree.json", "testdata/simple_tree.yaml"})
+	assert.EqualError(t, err, `testdata/simple_tree.yaml: duplicate tree name "simpleTree"`)
+	_, err = newServer([]string{"testdata/orphans.json"})
+	assert.EqualError(t, err, "testdata/orphans.json: orphan nodes not reachable from root: [3]")
+}
@@ -0,0 +1,50 @@
+package compare
+
+import (
+	"encoding/json"
+)
+
+// Between comparer, b is a list with the lower and upper bounds and a is
+// between them. The bounds are exclusive unless LowerInclusive or
+// UpperInclusive are set, and are compared like Greater and Lesser.
+type Between struct {
+	LowerInclusive bool `json:"lowerInclusive"`
+	UpperInclusive bool `json:"upperInclusive"`
+	// Numeric compares numbers of different types by value, see NumericComparer
+	Numeric bool `json:"numeric,omitempty"`
+}
+
+// Compare between imp
+func (bt *Between) Compare(a, b interface{}) bool {
+	return bt.between(a, b, bt.Numeric)
+}
+
+// CompareNumeric between imp
+func (bt *Between) CompareNumeric(a, b interface{}) bool {
+	return bt.between(a, b, true)
+}
+
+func (bt *Between) between(a, b interface{}, numeric bool) bool {
+	bounds, ok := b.([]interface{})
+	if !ok || len(bounds) != 2 {
+		return false
+	}
+	lower := Greater{Equal: bt.LowerInclusive, Numeric: numeric}
+	upper := Lesser{Equal: bt.UpperInclusive, Numeric: numeric}
+	return lower.Compare(a, bounds[0]) && upper.Compare(a, bounds[1])
+}
+
+// MarshalJSON ...
+func (bt *Between) MarshalJSON() ([]byte, error) {
+	return json.Marshal(&struct {
+		Comp           string `json:"type"`
+		LowerInclusive bool   `json:"lowerInclusive"`
+		UpperInclusive bool   `json:"upperInclusive"`
+		Numeric        bool   `json:"numeric,omitempty"`
+	}{
+		"between",
+		bt.LowerInclusive,
+		bt.UpperInclusive,
+		bt.Numeric,
+	})
+}
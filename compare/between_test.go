@@ -0,0 +1,40 @@
+package compare
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestBetweenCompare(t *testing.T) {
+	tests := map[string]struct {
+		comparer Between
+		inputA   interface{}
+		inputB   interface{}
+		expected bool
+	}{
+		"int inside":              {Between{}, 45, []interface{}{30, 60}, true},
+		"int lower exclusive":     {Between{}, 30, []interface{}{30, 60}, false},
+		"int lower inclusive":     {Between{LowerInclusive: true}, 30, []interface{}{30, 60}, true},
+		"int upper exclusive":     {Between{LowerInclusive: true}, 60, []interface{}{30, 60}, false},
+		"int upper inclusive":     {Between{UpperInclusive: true}, 60, []interface{}{30, 60}, true},
+		"int below":               {Between{LowerInclusive: true, UpperInclusive: true}, 29, []interface{}{30, 60}, false},
+		"int above":               {Between{LowerInclusive: true, UpperInclusive: true}, 61, []interface{}{30, 60}, false},
+		"int64 inside":            {Between{}, int64(5), []interface{}{int64(1), int64(10)}, true},
+		"float64 inside":          {Between{}, 0.5, []interface{}{0.0, 1.0}, true},
+		"float64 upper inclusive": {Between{UpperInclusive: true}, 1.0, []interface{}{0.0, 1.0}, true},
+		"string inside":           {Between{}, "m", []interface{}{"a", "z"}, true},
+		"different types":         {Between{}, 45, []interface{}{int64(30), int64(60)}, false},
+		"one bound":               {Between{}, 45, []interface{}{30}, false},
+		"not a list":              {Between{}, 45, 30, false},
+		"empty range":             {Between{LowerInclusive: true}, 30, []interface{}{30, 30}, false},
+		"single value range":      {Between{LowerInclusive: true, UpperInclusive: true}, 30, []interface{}{30, 30}, true},
+		"numeric different types": {Between{Numeric: true}, 45, []interface{}{int64(30), 60.5}, true},
+		"numeric upper bound":     {Between{Numeric: true}, uint8(60), []interface{}{int64(30), 60.0}, false},
+	}
+	for name, tc := range tests {
+		t.Run(name, func(t *testing.T) {
+			assert.Equal(t, tc.expected, tc.comparer.Compare(tc.inputA, tc.inputB))
+		})
+	}
+}
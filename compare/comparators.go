@@ -6,25 +6,64 @@ import (
 )
 
 // Equal comparer
-type Equal struct{}
+type Equal struct {
+	// Numeric compares numbers of different types by value, see NumericComparer
+	Numeric bool `json:"numeric,omitempty"`
+}
 
 // Greater comparer
 type Greater struct {
 	Equal bool `json:"equal"`
+	// Numeric compares numbers of different types by value, see NumericComparer
+	Numeric bool `json:"numeric,omitempty"`
 }
 
 // Lesser Comparer
 type Lesser struct {
 	Equal bool `json:"equal"`
+	// Numeric compares numbers of different types by value, see NumericComparer
+	Numeric bool `json:"numeric,omitempty"`
 }
 
 // Compare equal imp
 func (e *Equal) Compare(a, b interface{}) bool {
+	if e.Numeric {
+		return e.CompareNumeric(a, b)
+	}
+	return reflect.DeepEqual(a, b)
+}
+
+// CompareNumeric equal imp
+func (e *Equal) CompareNumeric(a, b interface{}) bool {
+	if c, ok := CompareNumbers(a, b); ok {
+		return c == 0
+	}
+	if IsNumber(a) && IsNumber(b) {
+		return false
+	}
 	return reflect.DeepEqual(a, b)
 }
 
 // Compare greater imp
 func (g *Greater) Compare(a, b interface{}) bool {
+	if g.Numeric {
+		return g.CompareNumeric(a, b)
+	}
+	return g.compare(a, b)
+}
+
+// CompareNumeric greater imp
+func (g *Greater) CompareNumeric(a, b interface{}) bool {
+	if c, ok := CompareNumbers(a, b); ok {
+		return c > 0 || (g.Equal && c == 0)
+	}
+	if IsNumber(a) && IsNumber(b) {
+		return false
+	}
+	return g.compare(a, b)
+}
+
+func (g *Greater) compare(a, b interface{}) bool {
 	if reflect.TypeOf(a) != reflect.TypeOf(b) {
 		return false
 	}
@@ -50,6 +89,13 @@ func (g *Greater) Compare(a, b interface{}) bool {
 			return aFloat64 >= bFloat64
 		}
 		return aFloat64 > bFloat64
+	case string:
+		aString, _ := a.(string)
+		bString, _ := b.(string)
+		if g.Equal {
+			return aString >= bString
+		}
+		return aString > bString
 	default:
 		return false
 	}
@@ -58,6 +104,24 @@ func (g *Greater) Compare(a, b interface{}) bool {
 
 // Compare lesser imp
 func (l *Lesser) Compare(a, b interface{}) bool {
+	if l.Numeric {
+		return l.CompareNumeric(a, b)
+	}
+	return l.compare(a, b)
+}
+
+// CompareNumeric lesser imp
+func (l *Lesser) CompareNumeric(a, b interface{}) bool {
+	if c, ok := CompareNumbers(a, b); ok {
+		return c < 0 || (l.Equal && c == 0)
+	}
+	if IsNumber(a) && IsNumber(b) {
+		return false
+	}
+	return l.compare(a, b)
+}
+
+func (l *Lesser) compare(a, b interface{}) bool {
 	if reflect.TypeOf(a) != reflect.TypeOf(b) {
 		return false
 	}
@@ -83,6 +147,13 @@ func (l *Lesser) Compare(a, b interface{}) bool {
 			return aFloat64 <= bFloat64
 		}
 		return aFloat64 < bFloat64
+	case string:
+		aString, _ := a.(string)
+		bString, _ := b.(string)
+		if l.Equal {
+			return aString <= bString
+		}
+		return aString < bString
 	default:
 		return false
 	}
@@ -92,30 +163,36 @@ func (l *Lesser) Compare(a, b interface{}) bool {
 // MarshalJSON ...
 func (e *Equal) MarshalJSON() ([]byte, error) {
 	return json.Marshal(&struct {
-		Comp string `json:"type"`
+		Comp    string `json:"type"`
+		Numeric bool   `json:"numeric,omitempty"`
 	}{
 		"eq",
+		e.Numeric,
 	})
 }
 
 // MarshalJSON ...
 func (l *Lesser) MarshalJSON() ([]byte, error) {
 	return json.Marshal(&struct {
-		Comp  string `json:"type"`
-		Equal bool   `json:"equal"`
+		Comp    string `json:"type"`
+		Equal   bool   `json:"equal"`
+		Numeric bool   `json:"numeric,omitempty"`
 	}{
 		"lt",
 		l.Equal,
+		l.Numeric,
 	})
 }
 
 // MarshalJSON ...
 func (g *Greater) MarshalJSON() ([]byte, error) {
 	return json.Marshal(&struct {
-		Comp  string `json:"type"`
-		Equal bool   `json:"equal"`
+		Comp    string `json:"type"`
+		Equal   bool   `json:"equal"`
+		Numeric bool   `json:"numeric,omitempty"`
 	}{
 		"gt",
 		g.Equal,
+		g.Numeric,
 	})
 }
@@ -1,6 +1,8 @@
 package compare
 
 import (
+	"encoding/json"
+	"math"
 	"testing"
 
 	"github.com/stretchr/testify/assert"
@@ -41,14 +43,17 @@ func TestGreaterCompare(t *testing.T) {
 		expected bool
 	}
 	testsGreater := map[string]testGreater{
-		"greater int":          {2, 1, true},
-		"not greater int":      {2, 20, false},
-		"greater int64":        {int64(2), int64(1), true},
-		"not greater int64":    {int64(0), int64(1), false},
-		"different types":      {"hi", 1, false},
-		"greater float64":      {4.14, 3.14, true},
-		"not grater float64":   {1.14, 2.71, false},
-		"not comparable types": {"asd", "asd", false},
+		"greater int":        {2, 1, true},
+		"not greater int":    {2, 20, false},
+		"greater int64":      {int64(2), int64(1), true},
+		"not greater int64":  {int64(0), int64(1), false},
+		"different types":    {"hi", 1, false},
+		"greater float64":    {4.14, 3.14, true},
+		"not grater float64": {1.14, 2.71, false},
+		"equal strings":      {"asd", "asd", false},
+		"greater string":     {"b", "abc", true},
+		"not greater string": {"Z", "a", false},
+		"bool":               {true, false, false},
 	}
 	gt := Greater{}
 	for name, tcs := range testsGreater {
@@ -61,6 +66,7 @@ func TestGreaterCompare(t *testing.T) {
 		"greater or equal int":     {2, 2, true},
 		"greater or equal int64":   {int64(1), int64(1), true},
 		"greater or equal float64": {3.14, 3.14, true},
+		"greater or equal string":  {"asd", "asd", true},
 	}
 	gt.Equal = true
 	for name, tcs := range testsGreaterOrEqual {
@@ -79,14 +85,17 @@ func TestLesserCompare(t *testing.T) {
 		expected bool
 	}
 	testsLesser := map[string]testLesser{
-		"lesser int":           {2, 1, false},
-		"not lesser int":       {2, 20, true},
-		"lesser int64":         {int64(2), int64(1), false},
-		"not lesser int64":     {int64(0), int64(1), true},
-		"different types":      {"hi", 1, false},
-		"lesser float64":       {4.14, 3.14, false},
-		"not lesser float64":   {1.14, 2.71, true},
-		"not comparable types": {"asd", "asd", false},
+		"lesser int":         {2, 1, false},
+		"not lesser int":     {2, 20, true},
+		"lesser int64":       {int64(2), int64(1), false},
+		"not lesser int64":   {int64(0), int64(1), true},
+		"different types":    {"hi", 1, false},
+		"lesser float64":     {4.14, 3.14, false},
+		"not lesser float64": {1.14, 2.71, true},
+		"equal strings":      {"asd", "asd", false},
+		"lesser string":      {"abc", "b", true},
+		"not lesser string":  {"a", "Z", false},
+		"bool":               {false, true, false},
 	}
 	lt := Lesser{}
 	for name, tcs := range testsLesser {
@@ -99,6 +108,7 @@ func TestLesserCompare(t *testing.T) {
 		"lesser or equal int":     {2, 2, true},
 		"lesser or equal int64":   {int64(1), int64(1), true},
 		"lesser or equal float64": {3.14, 3.14, true},
+		"lesser or equal string":  {"asd", "asd", true},
 	}
 	lt.Equal = true
 	for name, tcs := range testsLesserOrEqual {
@@ -108,3 +118,151 @@ func TestLesserCompare(t *testing.T) {
 		})
 	}
 }
+
+type namedInt int
+
+func TestCompareNumbers(t *testing.T) {
+	nan := math.NaN()
+	tests := map[string]struct {
+		a, b     interface{}
+		expected int
+		ok       bool
+	}{
+		"int and int64":               {1, int64(1), 0, true},
+		"int8 and uint16":             {int8(-1), uint16(1), -1, true},
+		"int32 and float32":           {int32(3), float32(2.5), 1, true},
+		"named int and int":           {namedInt(4), 4, 0, true},
+		"uintptr and uint8":           {uintptr(7), uint8(7), 0, true},
+		"max uint64 and max int64":    {uint64(math.MaxUint64), int64(math.MaxInt64), 1, true},
+		"negative int and uint64":     {-1, uint64(0), -1, true},
+		"max int64 and its float":     {int64(math.MaxInt64), float64(math.MaxInt64), -1, true},
+		"min int64 and its float":     {int64(math.MinInt64), float64(math.MinInt64), 0, true},
+		"int and float fraction":      {2, 2.5, -1, true},
+		"negative int and float":      {-2, -2.5, 1, true},
+		"int and float equal":         {2, 2.0, 0, true},
+		"int and huge float":          {int64(math.MaxInt64), 1e300, -1, true},
+		"int and huge negative float": {int64(math.MinInt64), -1e300, 1, true},
+		"uint64 and 2^64 float":       {uint64(math.MaxUint64), 18446744073709551616.0, -1, true},
+		"uint64 and float fraction":   {uint64(3), 3.25, -1, true},
+		"uint64 and negative float":   {uint64(0), -0.5, 1, true},
+		"uint and same float":         {uint(1 << 63), float64(1 << 63), 0, true},
+		"int and +inf":                {1, math.Inf(1), -1, true},
+		"-inf and uint":               {math.Inf(-1), uint(1), -1, true},
+		"float32 and float64":         {float32(0.5), 0.5, 0, true},
+		"float32 precision":           {float32(0.1), 0.1, 1, true},
+		"json number and int":         {json.Number("3"), 3, 0, true},
+		"json float and uint":         {json.Number("2.5"), uint(3), -1, true},
+		"invalid json number":         {json.Number("x"), 1, 0, false},
+		"nan and int":                 {nan, 1, 0, false},
+		"int and nan":                 {1, nan, 0, false},
+		"uint and nan":                {uint(1), nan, 0, false},
+		"nan and nan":                 {nan, nan, 0, false},
+		"string":                      {"1", 1, 0, false},
+		"bool":                        {true, 1, 0, false},
+		"nil":                         {nil, 0, 0, false},
+	}
+	for name, tc := range tests {
+		t.Run(name, func(t *testing.T) {
+			got, ok := CompareNumbers(tc.a, tc.b)
+			assert.Equal(t, tc.ok, ok)
+			assert.Equal(t, tc.expected, got)
+		})
+	}
+}
+
+func TestNumberOf(t *testing.T) {
+	tests := map[string]struct {
+		v       interface{}
+		i       int64
+		isInt   bool
+		f       float64
+		isFloat bool
+	}{
+		"int":            {v: -3, i: -3, isInt: true, f: -3},
+		"uint8":          {v: uint8(200), i: 200, isInt: true, f: 200},
+		"max uint64":     {v: uint64(math.MaxUint64), f: math.MaxUint64},
+		"float32":        {v: float32(0.5), f: 0.5, isFloat: true},
+		"integral float": {v: 2.0, f: 2, isFloat: true},
+		"json integer":   {v: json.Number("7"), i: 7, isInt: true, f: 7},
+		"json decimal":   {v: json.Number("7.5"), f: 7.5, isFloat: true},
+	}
+	for name, tc := range tests {
+		t.Run(name, func(t *testing.T) {
+			n, ok := NumberOf(tc.v)
+			assert.True(t, ok)
+			i, isInt := n.Int64()
+			assert.Equal(t, tc.isInt, isInt)
+			assert.Equal(t, tc.i, i)
+			assert.Equal(t, tc.f, n.Float64())
+			assert.Equal(t, tc.isFloat, n.IsFloat())
+		})
+	}
+	for _, v := range []interface{}{nil, "1", true, json.Number("x")} {
+		_, ok := NumberOf(v)
+		assert.False(t, ok, "%#v", v)
+	}
+}
+
+func TestNumericComparers(t *testing.T) {
+	nan := math.NaN()
+	type numericCase struct {
+		inputA   interface{}
+		inputB   interface{}
+		expected bool
+	}
+	tests := map[string]struct {
+		comparer interface {
+			Compare(a, b interface{}) bool
+			CompareNumeric(a, b interface{}) bool
+		}
+		cases map[string]numericCase
+	}{
+		"equal": {comparer: &Equal{}, cases: map[string]numericCase{
+			"int and int64":     {1, int64(1), true},
+			"uint8 and float64": {uint8(255), 255.0, true},
+			"different values":  {1, int64(2), false},
+			"fraction":          {1, 1.5, false},
+			"nan":               {nan, nan, false},
+			"strings":           {"a", "a", true},
+			"string and int":    {"1", 1, false},
+		}},
+		"greater": {comparer: &Greater{}, cases: map[string]numericCase{
+			"int and int64":  {2, int64(1), true},
+			"equal values":   {2, int64(2), false},
+			"int and float":  {2, 1.5, true},
+			"uint and int":   {uint64(math.MaxUint64), int64(math.MaxInt64), true},
+			"nan":            {nan, 1, false},
+			"strings":        {"b", "a", true},
+			"string and int": {"b", 1, false},
+		}},
+		"greater or equal": {comparer: &Greater{Equal: true}, cases: map[string]numericCase{
+			"equal values": {int16(2), 2.0, true},
+			"nan":          {nan, nan, false},
+		}},
+		"lesser": {comparer: &Lesser{}, cases: map[string]numericCase{
+			"int and int64": {1, int64(2), true},
+			"negative":      {int8(-1), uint(0), true},
+			"nan":           {1, nan, false},
+		}},
+		"lesser or equal": {comparer: &Lesser{Equal: true}, cases: map[string]numericCase{
+			"equal values": {float32(1.5), 1.5, true},
+		}},
+	}
+	for name, tc := range tests {
+		t.Run(name, func(t *testing.T) {
+			for caseName, c := range tc.cases {
+				t.Run(caseName, func(t *testing.T) {
+					assert.Equal(t, c.expected, tc.comparer.CompareNumeric(c.inputA, c.inputB))
+				})
+			}
+		})
+	}
+	t.Run("numeric field", func(t *testing.T) {
+		assert.False(t, (&Equal{}).Compare(1, int64(1)))
+		assert.True(t, (&Equal{Numeric: true}).Compare(1, int64(1)))
+		assert.False(t, (&Greater{}).Compare(2, int64(1)))
+		assert.True(t, (&Greater{Numeric: true}).Compare(2, int64(1)))
+		assert.False(t, (&Lesser{}).Compare(1, int64(2)))
+		assert.True(t, (&Lesser{Numeric: true}).Compare(1, int64(2)))
+	})
+}
@@ -0,0 +1,133 @@
+package compare
+
+import (
+	"encoding/json"
+
+	"github.com/sgrodriguez/ddt/value"
+)
+
+// Comparer compares the input value a with the value to compare b
+type Comparer interface {
+	Compare(a, b interface{}) bool
+}
+
+// Operand is a comparer of a composite comparer with its own value to
+// compare, the value can be nil for composite comparers
+type Operand struct {
+	Comparer Comparer     `json:"comparer"`
+	Value    *value.Value `json:"value,omitempty"`
+}
+
+// And comparer, a matches every operand. The value to compare b is not used.
+type And struct {
+	Operands []*Operand `json:"operands"`
+}
+
+// Or comparer, a matches at least one operand. The value to compare b is not
+// used.
+type Or struct {
+	Operands []*Operand `json:"operands"`
+}
+
+// Not comparer, a does not match the operand. The value to compare b is not
+// used.
+type Not struct {
+	Operand *Operand `json:"operand"`
+}
+
+// Compare and imp
+func (and *And) Compare(a, b interface{}) bool {
+	for _, op := range and.Operands {
+		if !op.compare(a, false) {
+			return false
+		}
+	}
+	return len(and.Operands) != 0
+}
+
+// CompareNumeric and imp, the operands are compared with numeric coercion
+func (and *And) CompareNumeric(a, b interface{}) bool {
+	for _, op := range and.Operands {
+		if !op.compare(a, true) {
+			return false
+		}
+	}
+	return len(and.Operands) != 0
+}
+
+// Compare or imp
+func (or *Or) Compare(a, b interface{}) bool {
+	for _, op := range or.Operands {
+		if op.compare(a, false) {
+			return true
+		}
+	}
+	return false
+}
+
+// CompareNumeric or imp, the operands are compared with numeric coercion
+func (or *Or) CompareNumeric(a, b interface{}) bool {
+	for _, op := range or.Operands {
+		if op.compare(a, true) {
+			return true
+		}
+	}
+	return false
+}
+
+// Compare not imp
+func (not *Not) Compare(a, b interface{}) bool {
+	return not.Operand != nil && !not.Operand.compare(a, false)
+}
+
+// CompareNumeric not imp, the operand is compared with numeric coercion
+func (not *Not) CompareNumeric(a, b interface{}) bool {
+	return not.Operand != nil && !not.Operand.compare(a, true)
+}
+
+func (op *Operand) compare(a interface{}, numeric bool) bool {
+	if op == nil || op.Comparer == nil {
+		return false
+	}
+	var b interface{}
+	if op.Value != nil {
+		b = op.Value.Value
+	}
+	if nc, ok := op.Comparer.(NumericComparer); ok && numeric {
+		return nc.CompareNumeric(a, b)
+	}
+	return op.Comparer.Compare(a, b)
+}
+
+// MarshalJSON ...
+func (and *And) MarshalJSON() ([]byte, error) {
+	return json.Marshal(&struct {
+		Comp     string     `json:"type"`
+		Operands []*Operand `json:"operands"`
+	}{
+		"and",
+		and.Operands,
+	})
+}
+
+// MarshalJSON ...
+func (or *Or) MarshalJSON() ([]byte, error) {
+	return json.Marshal(&struct {
+		Comp     string     `json:"type"`
+		Operands []*Operand `json:"operands"`
+	}{
+		"or",
+		or.Operands,
+	})
+}
+
+// MarshalJSON ...
+func (not *Not) MarshalJSON() ([]byte, error) {
+	return json.Marshal(&struct {
+		Comp    string   `json:"type"`
+		Operand *Operand `json:"operand"`
+	}{
+		"not",
+		not.Operand,
+	})
+}
@@ -0,0 +1,81 @@
+package compare
+
+import (
+	"encoding/json"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"github.com/sgrodriguez/ddt/value"
+)
+
+func operand(c Comparer, t value.Type, v interface{}) *Operand {
+	return &Operand{Comparer: c, Value: &value.Value{Type: t, Value: v}}
+}
+
+func TestLogicalCompare(t *testing.T) {
+	adult := &And{Operands: []*Operand{
+		operand(&Greater{Equal: true}, value.Int, 18),
+		operand(&Lesser{}, value.Int, 65),
+	}}
+	extremes := &Or{Operands: []*Operand{
+		operand(&Lesser{}, value.Int, 18),
+		operand(&Greater{Equal: true}, value.Int, 65),
+	}}
+	notBanned := &Not{Operand: operand(&Equal{}, value.String, "banned")}
+	nested := &Or{Operands: []*Operand{
+		{Comparer: adult},
+		{Comparer: &Not{Operand: operand(&Lesser{}, value.Int, 100)}},
+	}}
+	tests := map[string]struct {
+		comparer Comparer
+		input    interface{}
+		expected bool
+	}{
+		"and matches":          {adult, 30, true},
+		"and lower bound":      {adult, 18, true},
+		"and fails":            {adult, 65, false},
+		"or matches first":     {extremes, 10, true},
+		"or matches second":    {extremes, 70, true},
+		"or fails":             {extremes, 30, false},
+		"not matches":          {notBanned, "active", true},
+		"not fails":            {notBanned, "banned", false},
+		"nested and":           {nested, 40, true},
+		"nested not":           {nested, 120, true},
+		"nested fails":         {nested, 80, false},
+		"different types":      {adult, int64(30), false},
+		"empty and":            {&And{}, 1, false},
+		"empty or":             {&Or{}, 1, false},
+		"not without operand":  {&Not{}, 1, false},
+		"operand without comp": {&And{Operands: []*Operand{{}}}, 1, false},
+	}
+	for name, tc := range tests {
+		t.Run(name, func(t *testing.T) {
+			assert.Equal(t, tc.expected, tc.comparer.Compare(tc.input, nil))
+		})
+	}
+	t.Run("numeric", func(t *testing.T) {
+		assert.True(t, adult.CompareNumeric(int64(30), nil))
+		assert.True(t, extremes.CompareNumeric(70.5, nil))
+		assert.False(t, notBanned.CompareNumeric("banned", nil))
+		assert.True(t, (&Not{Operand: operand(&Equal{}, value.Int, 1)}).CompareNumeric(1.5, nil))
+		assert.False(t, (&Not{Operand: operand(&Equal{}, value.Int, 1)}).CompareNumeric(1.0, nil))
+	})
+}
+
+func TestLogicalMarshal(t *testing.T) {
+	c := &And{Operands: []*Operand{
+		operand(&Greater{}, value.Int, 18),
+		{Comparer: &Not{Operand: operand(&Equal{}, value.String, "x")}},
+	}}
+	b, err := json.Marshal(c)
+	require.NoError(t, err)
+	assert.JSONEq(t, `{"type":"and","operands":[
+		{"comparer":{"type":"gt","equal":false},"value":{"Value":18,"Type":"int"}},
+		{"comparer":{"type":"not","operand":{"comparer":{"type":"eq"},"value":{"Value":"x","Type":"string"}}}}
+	]}`, string(b))
+	b, err = json.Marshal(&Or{})
+	require.NoError(t, err)
+	assert.JSONEq(t, `{"type":"or","operands":null}`, string(b))
+}
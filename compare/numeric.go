@@ -0,0 +1,247 @@
+package compare
+
+import (
+	"encoding/json"
+	"math"
+	"reflect"
+)
+
+// NumericComparer is implemented by the comparers supporting numeric
+// coercion, CompareNumeric compares numbers of any integer or float kind by
+// their value instead of requiring the same type. Trees with NumericCoercion
+// call it instead of Compare.
+//
+// The numbers are compared exactly, without converting them to a narrower
+// type, so there is no overflow: a uint64 above math.MaxInt64 is greater than
+// any int64 and floats outside of the integer ranges compare as expected.
+// NaN is not equal, greater or lesser than any value, including NaN. Values
+// that are not numbers are compared like Compare does.
+type NumericComparer interface {
+	CompareNumeric(a, b interface{}) bool
+}
+
+type numberKind int
+
+const (
+	notNumber numberKind = iota
+	signed
+	unsigned
+	float
+)
+
+// Number holds a value of any integer or float kind, or a json.Number
+type Number struct {
+	kind numberKind
+	i    int64
+	u    uint64
+	f    float64
+}
+
+// NumberOf returns the number held by v, it is not ok when v is not of an
+// integer or float kind nor a json.Number
+func NumberOf(v interface{}) (Number, bool) {
+	n := numberOf(v)
+	return n, n.kind != notNumber
+}
+
+func numberOf(v interface{}) Number {
+	// the common types avoid the reflection
+	switch n := v.(type) {
+	case int:
+		return Number{kind: signed, i: int64(n)}
+	case int64:
+		return Number{kind: signed, i: n}
+	case float64:
+		return Number{kind: float, f: n}
+	case json.Number:
+		if i, err := n.Int64(); err == nil {
+			return Number{kind: signed, i: i}
+		}
+		if f, err := n.Float64(); err == nil {
+			return Number{kind: float, f: f}
+		}
+		return Number{}
+	case nil:
+		return Number{}
+	}
+	rv := reflect.ValueOf(v)
+	switch rv.Kind() {
+	case reflect.Int, reflect.Int8, reflect.Int16, reflect.Int32, reflect.Int64:
+		return Number{kind: signed, i: rv.Int()}
+	case reflect.Uint, reflect.Uint8, reflect.Uint16, reflect.Uint32, reflect.Uint64, reflect.Uintptr:
+		return Number{kind: unsigned, u: rv.Uint()}
+	case reflect.Float32, reflect.Float64:
+		return Number{kind: float, f: rv.Float()}
+	}
+	return Number{}
+}
+
+// IsFloat reports whether the number is of a float kind
+func (n Number) IsFloat() bool {
+	return n.kind == float
+}
+
+// Int64 returns the value of an integer number, it is not ok for floats and
+// unsigned integers greater than math.MaxInt64
+func (n Number) Int64() (int64, bool) {
+	switch n.kind {
+	case signed:
+		return n.i, true
+	case unsigned:
+		if n.u <= math.MaxInt64 {
+			return int64(n.u), true
+		}
+	}
+	return 0, false
+}
+
+// Float64 returns the value of the number as a float64
+func (n Number) Float64() float64 {
+	switch n.kind {
+	case signed:
+		return float64(n.i)
+	case unsigned:
+		return float64(n.u)
+	}
+	return n.f
+}
+
+// Compare compares the numbers by value like CompareNumbers
+func (n Number) Compare(m Number) (int, bool) {
+	return compareNumbers(n, m)
+}
+
+// IsNumber reports whether v is of an integer or float kind or a json.Number
+func IsNumber(v interface{}) bool {
+	return numberOf(v).kind != notNumber
+}
+
+// CompareNumbers compares two values of any integer or float kind by value,
+// returning -1, 0 or 1 when a is lesser, equal or greater than b. It is not
+// ok when a or b is not a number or is NaN.
+func CompareNumbers(a, b interface{}) (int, bool) {
+	return compareNumbers(numberOf(a), numberOf(b))
+}
+
+func compareNumbers(a, b Number) (int, bool) {
+	if a.kind == notNumber || b.kind == notNumber {
+		return 0, false
+	}
+	if b.kind < a.kind {
+		c, ok := compareNumbers(b, a)
+		return -c, ok
+	}
+	// a.kind <= b.kind
+	switch {
+	case a.kind == signed && b.kind == signed:
+		return compareInt64(a.i, b.i), true
+	case a.kind == signed && b.kind == unsigned:
+		if a.i < 0 {
+			return -1, true
+		}
+		return compareUint64(uint64(a.i), b.u), true
+	case a.kind == unsigned && b.kind == unsigned:
+		return compareUint64(a.u, b.u), true
+	case a.kind == signed && b.kind == float:
+		return compareIntFloat(a.i, b.f)
+	case a.kind == unsigned && b.kind == float:
+		return compareUintFloat(a.u, b.f)
+	}
+	if math.IsNaN(a.f) || math.IsNaN(b.f) {
+		return 0, false
+	}
+	return compareFloat64(a.f, b.f), true
+}
+
+// 2^63 and 2^64 are exact float64 values
+const (
+	twoTo63 = float64(1 << 63)
+	twoTo64 = twoTo63 * 2
+)
+
+func compareIntFloat(i int64, f float64) (int, bool) {
+	switch {
+	case math.IsNaN(f):
+		return 0, false
+	case f >= twoTo63:
+		return -1, true
+	case f < -twoTo63:
+		return 1, true
+	}
+	t := math.Trunc(f)
+	if c := compareInt64(i, int64(t)); c != 0 {
+		return c, true
+	}
+	return compareFloat64(t, f), true
+}
+
+func compareUintFloat(u uint64, f float64) (int, bool) {
+	switch {
+	case math.IsNaN(f):
+		return 0, false
+	case f >= twoTo64:
+		return -1, true
+	case f < 0:
+		return 1, true
+	}
+	t := math.Trunc(f)
+	if c := compareUint64(u, uint64(t)); c != 0 {
+		return c, true
+	}
+	return compareFloat64(t, f), true
+}
+
+func compareInt64(a, b int64) int {
+	switch {
+	case a < b:
+		return -1
+	case a > b:
+		return 1
+	}
+	return 0
+}
+
+func compareUint64(a, b uint64) int {
+	switch {
+	case a < b:
+		return -1
+	case a > b:
+		return 1
+	}
+	return 0
+}
+
+func compareFloat64(a, b float64) int {
+	switch {
+	case a < b:
+		return -1
+	case a > b:
+		return 1
+	}
+	return 0
+}
+
+// numberKey returns a map key identifying the value of a number, numbers of
+// different kinds with the same value have the same key. NaN has no key.
+func numberKey(n Number) (interface{}, bool) {
+	switch n.kind {
+	case signed:
+		return n.i, true
+	case unsigned:
+		if n.u <= math.MaxInt64 {
+			return int64(n.u), true
+		}
+		return n.u, true
+	case float:
+		switch {
+		case math.IsNaN(n.f):
+			return nil, false
+		case n.f != math.Trunc(n.f) || n.f >= twoTo64 || n.f < -twoTo63:
+			return n.f, true
+		case n.f >= twoTo63:
+			return uint64(n.f), true
+		}
+		return int64(n.f), true
+	}
+	return nil, false
+}
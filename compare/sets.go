@@ -0,0 +1,135 @@
+package compare
+
+import (
+	"encoding/json"
+	"reflect"
+)
+
+// In comparer, a is equal to one of the elements of the list b. The list is
+// searched linearly, the compiled Evaluator looks it up in a Set instead.
+type In struct {
+	// Numeric compares numbers of different types by value, see NumericComparer
+	Numeric bool `json:"numeric,omitempty"`
+}
+
+// NotIn comparer, b is a list and a is not equal to any of its elements. The
+// list is searched like for the In comparer.
+type NotIn struct {
+	// Numeric compares numbers of different types by value, see NumericComparer
+	Numeric bool `json:"numeric,omitempty"`
+}
+
+// Compare in imp
+func (i *In) Compare(a, b interface{}) bool {
+	list, ok := b.([]interface{})
+	return ok && contains(list, a, i.Numeric)
+}
+
+// CompareNumeric in imp
+func (i *In) CompareNumeric(a, b interface{}) bool {
+	list, ok := b.([]interface{})
+	return ok && contains(list, a, true)
+}
+
+// Compare not in imp
+func (n *NotIn) Compare(a, b interface{}) bool {
+	list, ok := b.([]interface{})
+	return ok && !contains(list, a, n.Numeric)
+}
+
+// CompareNumeric not in imp
+func (n *NotIn) CompareNumeric(a, b interface{}) bool {
+	list, ok := b.([]interface{})
+	return ok && !contains(list, a, true)
+}
+
+// contains compares v with every element of the list like the Equal comparer
+func contains(list []interface{}, v interface{}, numeric bool) bool {
+	eq := Equal{Numeric: numeric}
+	for _, e := range list {
+		if eq.Compare(v, e) {
+			return true
+		}
+	}
+	return false
+}
+
+// MarshalJSON ...
+func (i *In) MarshalJSON() ([]byte, error) {
+	return marshalNumericType("in", i.Numeric)
+}
+
+// MarshalJSON ...
+func (n *NotIn) MarshalJSON() ([]byte, error) {
+	return marshalNumericType("notIn", n.Numeric)
+}
+
+func marshalNumericType(comp string, numeric bool) ([]byte, error) {
+	return json.Marshal(&struct {
+		Comp    string `json:"type"`
+		Numeric bool   `json:"numeric,omitempty"`
+	}{
+		comp,
+		numeric,
+	})
+}
+
+// Set is a set of values, two values are the same element when they are
+// equal and have the same type like for the Equal comparer
+type Set struct {
+	elems map[interface{}]struct{}
+	// numbers holds the numeric elements by value, see numberKey
+	numbers map[interface{}]struct{}
+	// others holds the elements that can not be map keys
+	others []interface{}
+}
+
+// NewSet creates a set with the elements of a list
+func NewSet(list []interface{}) *Set {
+	s := &Set{elems: make(map[interface{}]struct{}, len(list)), numbers: map[interface{}]struct{}{}}
+	for _, e := range list {
+		if key, ok := numberKey(numberOf(e)); ok {
+			s.numbers[key] = struct{}{}
+		}
+		if hashable(e) {
+			s.elems[e] = struct{}{}
+			continue
+		}
+		s.others = append(s.others, e)
+	}
+	return s
+}
+
+// Contains reports whether v is an element of the set
+func (s *Set) Contains(v interface{}) bool {
+	if hashable(v) {
+		_, ok := s.elems[v]
+		return ok
+	}
+	for _, e := range s.others {
+		if reflect.DeepEqual(e, v) {
+			return true
+		}
+	}
+	return false
+}
+
+// ContainsNumeric reports whether v is an element of the set, numbers of
+// different types are the same element when they have the same value
+func (s *Set) ContainsNumeric(v interface{}) bool {
+	n := numberOf(v)
+	if n.kind == notNumber {
+		return s.Contains(v)
+	}
+	key, ok := numberKey(n)
+	if !ok {
+		return false
+	}
+	_, ok = s.numbers[key]
+	return ok
+}
+
+func hashable(v interface{}) bool {
+	t := reflect.TypeOf(v)
+	return t == nil || t.Comparable()
+}
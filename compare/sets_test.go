@@ -0,0 +1,93 @@
+package compare
+
+import (
+	"fmt"
+	"math"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestInCompare(t *testing.T) {
+	large := make([]interface{}, 100)
+	for i := range large {
+		large[i] = fmt.Sprintf("c%d", i)
+	}
+	large = append(large, 7, int64(8), 1.5, true)
+	small := []interface{}{"UY", "AR", 3}
+	tests := map[string]struct {
+		inputA   interface{}
+		inputB   interface{}
+		expected bool
+	}{
+		"in small list":           {"AR", small, true},
+		"not in small list":       {"BR", small, false},
+		"int in small list":       {3, small, true},
+		"int64 not in small list": {int64(3), small, false},
+		"in large list":           {"c42", large, true},
+		"not in large list":       {"c100", large, false},
+		"int in large list":       {7, large, true},
+		"int64 in large list":     {int64(8), large, true},
+		"int not int64":           {8, large, false},
+		"float in large list":     {1.5, large, true},
+		"bool in large list":      {true, large, true},
+		"unhashable input":        {[]int{1}, large, false},
+		"nil input":               {nil, large, false},
+		"not a list":              {"UY", "UY", false},
+		"empty list":              {"UY", []interface{}{}, false},
+	}
+	in, notIn := &In{}, &NotIn{}
+	for name, tc := range tests {
+		t.Run(name, func(t *testing.T) {
+			assert.Equal(t, tc.expected, in.Compare(tc.inputA, tc.inputB))
+			_, isList := tc.inputB.([]interface{})
+			assert.Equal(t, isList && !tc.expected, notIn.Compare(tc.inputA, tc.inputB))
+		})
+	}
+}
+
+func TestSet(t *testing.T) {
+	s := NewSet([]interface{}{"a", 1, []int{1, 2}})
+	assert.True(t, s.Contains("a"))
+	assert.True(t, s.Contains(1))
+	assert.True(t, s.Contains([]int{1, 2}))
+	assert.False(t, s.Contains([]int{1}))
+	assert.False(t, s.Contains(int64(1)))
+	assert.False(t, s.Contains(nil))
+}
+
+func TestInCompareNumeric(t *testing.T) {
+	large := make([]interface{}, 20)
+	for i := range large {
+		large[i] = i * 10
+	}
+	large = append(large, uint64(math.MaxUint64), 0.5, math.NaN(), "a")
+	small := []interface{}{1, 2.5, "a"}
+	tests := map[string]struct {
+		inputA   interface{}
+		inputB   interface{}
+		expected bool
+	}{
+		"int64 in small list":        {int64(1), small, true},
+		"float in small list":        {1.0, small, true},
+		"float32 in small list":      {float32(2.5), small, true},
+		"string in small list":       {"a", small, true},
+		"int64 in large list":        {int64(30), large, true},
+		"uint8 in large list":        {uint8(190), large, true},
+		"integral float large list":  {40.0, large, true},
+		"fraction not in large list": {40.5, large, false},
+		"float32 in large list":      {float32(0.5), large, true},
+		"max uint64 in large list":   {uint64(math.MaxUint64), large, true},
+		"float 2^64 not in list":     {18446744073709551616.0, large, false},
+		"nan not in large list":      {math.NaN(), large, false},
+		"string in large list":       {"a", large, true},
+	}
+	in, notIn := &In{}, &NotIn{}
+	for name, tc := range tests {
+		t.Run(name, func(t *testing.T) {
+			assert.Equal(t, tc.expected, in.CompareNumeric(tc.inputA, tc.inputB))
+			assert.Equal(t, !tc.expected, notIn.CompareNumeric(tc.inputA, tc.inputB))
+			assert.Equal(t, tc.expected, (&In{Numeric: true}).Compare(tc.inputA, tc.inputB))
+		})
+	}
+}
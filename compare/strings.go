@@ -0,0 +1,136 @@
+package compare
+
+import (
+	"encoding/json"
+	"regexp"
+	"strings"
+)
+
+// Prefix comparer, a starts with b
+type Prefix struct{}
+
+// Suffix comparer, a ends with b
+type Suffix struct{}
+
+// Contains comparer, a contains the substring b
+type Contains struct{}
+
+// EqualFold comparer, a is equal to b ignoring case
+type EqualFold struct{}
+
+// Regex comparer, a matches the regular expression b. The pattern given to
+// NewRegex or Compile is compiled once, other patterns are compiled on every
+// comparison and an invalid pattern never matches.
+type Regex struct {
+	re *regexp.Regexp
+}
+
+// NewRegex creates a regex comparer with the pattern compiled
+func NewRegex(pattern string) (*Regex, error) {
+	r := &Regex{}
+	if err := r.Compile(pattern); err != nil {
+		return nil, err
+	}
+	return r, nil
+}
+
+// Compare prefix imp
+func (p *Prefix) Compare(a, b interface{}) bool {
+	aString, bString, ok := strings2(a, b)
+	return ok && strings.HasPrefix(aString, bString)
+}
+
+// Compare suffix imp
+func (s *Suffix) Compare(a, b interface{}) bool {
+	aString, bString, ok := strings2(a, b)
+	return ok && strings.HasSuffix(aString, bString)
+}
+
+// Compare contains imp
+func (c *Contains) Compare(a, b interface{}) bool {
+	aString, bString, ok := strings2(a, b)
+	return ok && strings.Contains(aString, bString)
+}
+
+// Compare equal fold imp
+func (e *EqualFold) Compare(a, b interface{}) bool {
+	aString, bString, ok := strings2(a, b)
+	return ok && strings.EqualFold(aString, bString)
+}
+
+// Compare regex imp
+func (r *Regex) Compare(a, b interface{}) bool {
+	aString, pattern, ok := strings2(a, b)
+	if !ok {
+		return false
+	}
+	re, err := r.Regexp(pattern)
+	return err == nil && re.MatchString(aString)
+}
+
+// Compile compiles the pattern compared by the comparer, it is called
+// decoding a node with its value to compare. It must not be called while the
+// comparer is in use.
+func (r *Regex) Compile(pattern string) error {
+	if r.re != nil && r.re.String() == pattern {
+		return nil
+	}
+	re, err := regexp.Compile(pattern)
+	if err != nil {
+		return err
+	}
+	r.re = re
+	return nil
+}
+
+// Regexp returns the pattern compiled by Compile, or compiles it when it is
+// another pattern
+func (r *Regex) Regexp(pattern string) (*regexp.Regexp, error) {
+	if re := r.re; re != nil && re.String() == pattern {
+		return re, nil
+	}
+	return regexp.Compile(pattern)
+}
+
+// strings2 returns a and b when both are strings
+func strings2(a, b interface{}) (string, string, bool) {
+	aString, ok := a.(string)
+	if !ok {
+		return "", "", false
+	}
+	bString, ok := b.(string)
+	return aString, bString, ok
+}
+
+// MarshalJSON ...
+func (p *Prefix) MarshalJSON() ([]byte, error) {
+	return marshalType("prefix")
+}
+
+// MarshalJSON ...
+func (s *Suffix) MarshalJSON() ([]byte, error) {
+	return marshalType("suffix")
+}
+
+// MarshalJSON ...
+func (c *Contains) MarshalJSON() ([]byte, error) {
+	return marshalType("contains")
+}
+
+// MarshalJSON ...
+func (e *EqualFold) MarshalJSON() ([]byte, error) {
+	return marshalType("eqFold")
+}
+
+// MarshalJSON ...
+func (r *Regex) MarshalJSON() ([]byte, error) {
+	return marshalType("regex")
+}
+
+func marshalType(comp string) ([]byte, error) {
+	return json.Marshal(&struct {
+		Comp string `json:"type"`
+	}{
+		comp,
+	})
+}
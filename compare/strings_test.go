@@ -0,0 +1,91 @@
+package compare
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestStringComparers(t *testing.T) {
+	type stringCase struct {
+		inputA   interface{}
+		inputB   interface{}
+		expected bool
+	}
+	tests := map[string]struct {
+		comparer interface {
+			Compare(a, b interface{}) bool
+		}
+		cases map[string]stringCase
+	}{
+		"prefix": {comparer: &Prefix{}, cases: map[string]stringCase{
+			"has prefix":     {"santiago", "san", true},
+			"empty prefix":   {"santiago", "", true},
+			"not prefix":     {"santiago", "ago", false},
+			"not string":     {1, "1", false},
+			"not string arg": {"1", 1, false},
+		}},
+		"suffix": {comparer: &Suffix{}, cases: map[string]stringCase{
+			"has suffix": {"ana@example.com", "@example.com", true},
+			"not suffix": {"ana@example.com", "example", false},
+		}},
+		"contains": {comparer: &Contains{}, cases: map[string]stringCase{
+			"contains":     {"hello world", "o w", true},
+			"not contains": {"hello world", "ow", false},
+			"case":         {"hello", "Hello", false},
+		}},
+		"equal fold": {comparer: &EqualFold{}, cases: map[string]stringCase{
+			"same case":      {"LUCIA", "LUCIA", true},
+			"different case": {"Lucía", "LUCÍA", true},
+			"different":      {"Lucia", "Lucía", false},
+			"not string":     {true, "true", false},
+		}},
+		"regex": {comparer: &Regex{}, cases: map[string]stringCase{
+			"match":           {"AB-1234", `^[A-Z]{2}-\d{4}$`, true},
+			"not match":       {"AB-123", `^[A-Z]{2}-\d{4}$`, false},
+			"partial match":   {"xx AB-1234", `[A-Z]{2}-\d{4}`, true},
+			"invalid pattern": {"(", "(", false},
+			"not string":      {1234, `\d+`, false},
+		}},
+	}
+	for name, tc := range tests {
+		t.Run(name, func(t *testing.T) {
+			for caseName, c := range tc.cases {
+				t.Run(caseName, func(t *testing.T) {
+					assert.Equal(t, c.expected, tc.comparer.Compare(c.inputA, c.inputB))
+				})
+			}
+		})
+	}
+}
+
+func TestRegex_Compile(t *testing.T) {
+	r := &Regex{}
+	assert.NoError(t, r.Compile(`a+`))
+	re, err := r.Regexp(`a+`)
+	assert.NoError(t, err)
+	assert.Same(t, r.re, re)
+	assert.NoError(t, r.Compile(`a+`))
+	assert.Same(t, re, r.re)
+	other, err := r.Regexp(`b+`)
+	assert.NoError(t, err)
+	assert.NotSame(t, re, other)
+	assert.True(t, r.Compare("b", `b+`))
+
+	assert.Error(t, r.Compile(`a(`))
+	assert.Same(t, re, r.re)
+	_, err = r.Regexp(`a(`)
+	assert.Error(t, err)
+}
+
+func TestNewRegex(t *testing.T) {
+	r, err := NewRegex(`^a+$`)
+	require.NoError(t, err)
+	re, err := r.Regexp(`^a+$`)
+	require.NoError(t, err)
+	assert.Same(t, r.re, re)
+	assert.True(t, r.Compare("aa", `^a+$`))
+	_, err = NewRegex(`a(`)
+	assert.Error(t, err)
+}
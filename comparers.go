@@ -0,0 +1,166 @@
+package ddt
+
+import (
+	"encoding/json"
+	"errors"
+	"fmt"
+	"sync"
+
+	"github.com/sgrodriguez/ddt/compare"
+	"github.com/sgrodriguez/ddt/value"
+)
+
+// ComparerFactory creates a comparer from its json, the whole comparer object
+// including its type (ie {"type": "gt", "equal": true}). Comparers created by
+// a factory must marshal the same type so trees round trip.
+type ComparerFactory func(data json.RawMessage) (Comparer, error)
+
+// ErrInvalidComparer is returned decoding a comparer without a registered type
+var ErrInvalidComparer = errors.New("invalid comparer")
+
+// compositeComparers are decoded by the registry itself so their operands use
+// the same factories, they can not be replaced
+var compositeComparers = map[string]bool{"and": true, "or": true, "not": true}
+
+var registry = struct {
+	sync.RWMutex
+	factories map[string]ComparerFactory
+}{factories: map[string]ComparerFactory{
+	"eq":       jsonComparer(func() Comparer { return &compare.Equal{} }),
+	"lt":       jsonComparer(func() Comparer { return &compare.Lesser{} }),
+	"gt":       jsonComparer(func() Comparer { return &compare.Greater{} }),
+	"prefix":   jsonComparer(func() Comparer { return &compare.Prefix{} }),
+	"suffix":   jsonComparer(func() Comparer { return &compare.Suffix{} }),
+	"contains": jsonComparer(func() Comparer { return &compare.Contains{} }),
+	"eqFold":   jsonComparer(func() Comparer { return &compare.EqualFold{} }),
+	"regex":    jsonComparer(func() Comparer { return &compare.Regex{} }),
+	"in":       jsonComparer(func() Comparer { return &compare.In{} }),
+	"notIn":    jsonComparer(func() Comparer { return &compare.NotIn{} }),
+	"between":  jsonComparer(func() Comparer { return &compare.Between{} }),
+}}
+
+// RegisterComparer registers the factory of a comparer type for every tree,
+// replacing the registered one with the same type. Tree.Comparers take
+// precedence over the registered comparers. It panics if the factory is nil
+// or the type is one of the composite comparers (and, or, not).
+func RegisterComparer(comparerType string, factory ComparerFactory) {
+	if factory == nil {
+		panic("ddt: RegisterComparer factory is nil")
+	}
+	if compositeComparers[comparerType] {
+		panic("ddt: RegisterComparer can not replace composite comparer " + comparerType)
+	}
+	registry.Lock()
+	defer registry.Unlock()
+	registry.factories[comparerType] = factory
+}
+
+func registeredComparer(comparerType string) (ComparerFactory, bool) {
+	registry.RLock()
+	defer registry.RUnlock()
+	factory, ok := registry.factories[comparerType]
+	return factory, ok
+}
+
+// jsonComparer is the factory of a comparer decoded with encoding/json
+func jsonComparer(newComparer func() Comparer) ComparerFactory {
+	return func(data json.RawMessage) (Comparer, error) {
+		c := newComparer()
+		if err := json.Unmarshal(data, c); err != nil {
+			return nil, err
+		}
+		return c, nil
+	}
+}
+
+// createComparatorFromJSON creates a comparer with the registered factories
+func createComparatorFromJSON(message json.RawMessage) (Comparer, error) {
+	return decodeComparer(message, nil)
+}
+
+// decodeComparer creates a comparer with the given factories, falling back to
+// the registered ones. The operands of composite comparers are decoded the same way.
+func decodeComparer(data json.RawMessage, factories map[string]ComparerFactory) (Comparer, error) {
+	aux := &struct {
+		Comp string `json:"type"`
+	}{}
+	if err := json.Unmarshal(data, aux); err != nil {
+		return nil, err
+	}
+	if compositeComparers[aux.Comp] {
+		return decodeComposite(aux.Comp, data, factories)
+	}
+	factory, ok := factories[aux.Comp]
+	if !ok {
+		factory, ok = registeredComparer(aux.Comp)
+	}
+	if !ok {
+		return nil, fmt.Errorf("%w: %q", ErrInvalidComparer, aux.Comp)
+	}
+	comp, err := factory(data)
+	if err != nil {
+		return nil, err
+	}
+	if comp == nil {
+		return nil, fmt.Errorf("%w: %q factory returned nil", ErrInvalidComparer, aux.Comp)
+	}
+	return comp, nil
+}
+
+func decodeComposite(comparerType string, data json.RawMessage, factories map[string]ComparerFactory) (Comparer, error) {
+	aux := &struct {
+		Operands []*jsonOperand `json:"operands"`
+		Operand  *jsonOperand   `json:"operand"`
+	}{}
+	if err := json.Unmarshal(data, aux); err != nil {
+		return nil, err
+	}
+	if comparerType == "not" {
+		operand, err := createOperandFromJSON(aux.Operand, factories)
+		if err != nil {
+			return nil, err
+		}
+		return &compare.Not{Operand: operand}, nil
+	}
+	operands := make([]*compare.Operand, len(aux.Operands))
+	for i, op := range aux.Operands {
+		operand, err := createOperandFromJSON(op, factories)
+		if err != nil {
+			return nil, err
+		}
+		operands[i] = operand
+	}
+	if comparerType == "and" {
+		return &compare.And{Operands: operands}, nil
+	}
+	return &compare.Or{Operands: operands}, nil
+}
+
+// jsonOperand is an operand of a composite comparer, its comparer is decoded
+// like the comparer of a node
+type jsonOperand struct {
+	Comparer json.RawMessage `json:"comparer"`
+	Value    *value.Value    `json:"value"`
+}
+
+func createOperandFromJSON(op *jsonOperand, factories map[string]ComparerFactory) (*compare.Operand, error) {
+	if op == nil || op.Comparer == nil {
+		return nil, errors.New("invalid comparer operand")
+	}
+	comp, err := decodeComparer(op.Comparer, factories)
+	if err != nil {
+		return nil, err
+	}
+	return &compare.Operand{Comparer: comp, Value: op.Value}, nil
+}
+
+// comparerType returns the json type of a comparer
+func comparerType(c Comparer) string {
+	aux := struct {
+		Comp string `json:"type"`
+	}{}
+	if b, err := json.Marshal(c); err == nil && json.Unmarshal(b, &aux) == nil && aux.Comp != "" {
+		return aux.Comp
+	}
+	return fmt.Sprintf("%T", c)
+}
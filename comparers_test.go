@@ -0,0 +1,135 @@
+package ddt
+
+import (
+	"encoding/json"
+	"errors"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+// multiple matches integers multiple of the value to compare
+type multiple struct {
+	Strict bool `json:"strict"`
+}
+
+func (m *multiple) Compare(a, b interface{}) bool {
+	x, ok := a.(int)
+	y, ok2 := b.(int)
+	if !ok || !ok2 || y == 0 {
+		return false
+	}
+	return x%y == 0 && (!m.Strict || x != y)
+}
+
+// MarshalJSON ...
+func (m *multiple) MarshalJSON() ([]byte, error) {
+	return json.Marshal(&struct {
+		Comp   string `json:"type"`
+		Strict bool   `json:"strict"`
+	}{Comp: "multiple", Strict: m.Strict})
+}
+
+func multipleFactory(data json.RawMessage) (Comparer, error) {
+	return jsonComparer(func() Comparer { return &multiple{} })(data)
+}
+
+const multiplesTree = `{"name": "multiples", "root": {"children": [
+	{"comparer": {"type": "multiple", "strict": true}, "valueToCompare": {"Value": 3, "Type": "int"}, "result": {"Value": "fizz", "Type": "string"}},
+	{"comparer": {"type": "or", "operands": [
+		{"comparer": {"type": "multiple"}, "value": {"Value": 5, "Type": "int"}},
+		{"comparer": {"type": "eq"}, "value": {"Value": 1, "Type": "int"}}
+	]}, "result": {"Value": "buzz", "Type": "string"}},
+	{"default": true, "result": {"Value": "none", "Type": "string"}}
+]}}`
+
+func TestTreeComparers(t *testing.T) {
+	tree := &Tree{Comparers: map[string]ComparerFactory{"multiple": multipleFactory}}
+	require.NoError(t, json.Unmarshal([]byte(multiplesTree), tree))
+	tests := map[string]struct {
+		input    int
+		expected string
+	}{
+		"strict multiple": {input: 9, expected: "fizz"},
+		"same value":      {input: 3, expected: "none"},
+		"operand":         {input: 10, expected: "buzz"},
+		"other operand":   {input: 1, expected: "buzz"},
+		"no match":        {input: 7, expected: "none"},
+	}
+	evaluator, err := Compile(tree)
+	require.NoError(t, err)
+	for name, tc := range tests {
+		t.Run(name, func(t *testing.T) {
+			res, err := ResolveTree(tree, tc.input)
+			require.NoError(t, err)
+			assert.Equal(t, tc.expected, res)
+			res, err = evaluator.Resolve(tc.input)
+			require.NoError(t, err)
+			assert.Equal(t, tc.expected, res)
+		})
+	}
+	t.Run("round trip", func(t *testing.T) {
+		for name, marshal := range map[string]func() ([]byte, error){"flat": tree.MarshalJSON, "nested": tree.MarshalNestedJSON} {
+			b, err := marshal()
+			require.NoError(t, err, name)
+			decoded := &Tree{Comparers: tree.Comparers}
+			require.NoError(t, json.Unmarshal(b, decoded), name)
+			assert.Equal(t, &multiple{Strict: true}, decoded.Root.Children[0].Comparer, name)
+		}
+	})
+	t.Run("holder", func(t *testing.T) {
+		holder, err := NewHolder(tree)
+		require.NoError(t, err)
+		require.NoError(t, holder.LoadJSON([]byte(multiplesTree)))
+		assert.NotNil(t, holder.Tree().Comparers["multiple"])
+	})
+	t.Run("unknown type", func(t *testing.T) {
+		err := json.Unmarshal([]byte(multiplesTree), &Tree{})
+		assert.True(t, errors.Is(err, ErrInvalidComparer))
+		assert.EqualError(t, err, `invalid comparer: "multiple"`)
+	})
+	t.Run("overrides registered", func(t *testing.T) {
+		tree := &Tree{Comparers: map[string]ComparerFactory{
+			"multiple": multipleFactory,
+			"eq": func(json.RawMessage) (Comparer, error) {
+				return &multiple{}, nil
+			},
+		}}
+		require.NoError(t, json.Unmarshal([]byte(multiplesTree), tree))
+		res, err := ResolveTree(tree, 2)
+		require.NoError(t, err)
+		assert.Equal(t, "buzz", res)
+	})
+	t.Run("factory errors", func(t *testing.T) {
+		factoryErr := errors.New("factory failed")
+		for name, factory := range map[string]ComparerFactory{
+			"error": func(json.RawMessage) (Comparer, error) { return nil, factoryErr },
+			"nil":   func(json.RawMessage) (Comparer, error) { return nil, nil },
+		} {
+			err := json.Unmarshal([]byte(multiplesTree), &Tree{Comparers: map[string]ComparerFactory{"multiple": factory}})
+			assert.Error(t, err, name)
+		}
+		err := json.Unmarshal([]byte(multiplesTree), &Tree{Comparers: map[string]ComparerFactory{
+			"multiple": func(json.RawMessage) (Comparer, error) { return nil, factoryErr },
+		}})
+		assert.True(t, errors.Is(err, factoryErr))
+	})
+}
+
+func TestRegisterComparer(t *testing.T) {
+	RegisterComparer("multiple", multipleFactory)
+	defer func() {
+		registry.Lock()
+		delete(registry.factories, "multiple")
+		registry.Unlock()
+	}()
+	var tree Tree
+	require.NoError(t, json.Unmarshal([]byte(multiplesTree), &tree))
+	res, err := ResolveTree(&tree, 6)
+	require.NoError(t, err)
+	assert.Equal(t, "fizz", res)
+
+	assert.Panics(t, func() { RegisterComparer("and", multipleFactory) })
+	assert.Panics(t, func() { RegisterComparer("other", nil) })
+}
@@ -0,0 +1,479 @@
+package ddt
+
+import (
+	"context"
+	"errors"
+	"reflect"
+	"strings"
+	"sync"
+
+	"github.com/sgrodriguez/ddt/compare"
+	"github.com/sgrodriguez/ddt/function"
+	"github.com/sgrodriguez/ddt/value"
+)
+
+// Evaluator is an immutable compiled version of a tree, it resolves inputs
+// like ResolveTree avoiding the per call reflection lookups and type switches.
+// The struct fields and methods used by the default pre process functions are
+// resolved once per input type, and the comparers are specialized for the
+// type of the value to compare. An Evaluator is safe for concurrent use and
+// does not see later modifications of the compiled tree.
+type Evaluator struct {
+	root          *compiledNode
+	defaultResult interface{}
+	hasDefault    bool
+}
+
+type compiledNode struct {
+	id           int
+	leaf         bool
+	result       interface{}
+	hasResult    bool
+	steps        []*compiledStep
+	children     []*compiledChild
+	defaultChild *compiledNode
+	// preProcessFn, pipeline and expression are the names recorded by a trace
+	preProcessFn string
+	pipeline     []string
+	expression   string
+}
+
+// compiledStep is the pre process function of a node or a step of its pipeline
+type compiledStep struct {
+	fnName     string
+	expression string
+	extract    extractor
+}
+
+type compiledChild struct {
+	node  *compiledNode
+	match matcher
+	// comparer and valueToCompare are recorded by a trace
+	comparer       Comparer
+	valueToCompare interface{}
+}
+
+// extractor returns the value to compare of a node given the input
+type extractor func(ctx context.Context, input interface{}) (reflect.Value, error)
+
+// matcher reports whether the value to compare matches a child node
+type matcher func(v reflect.Value) bool
+
+// Compile validates the tree and compiles it into an Evaluator
+func Compile(t *Tree) (*Evaluator, error) {
+	if err := t.Validate(); err != nil {
+		return nil, err
+	}
+	e := &Evaluator{root: compileNode(t.Root, t.NumericCoercion)}
+	if t.DefaultResult != nil {
+		e.defaultResult = t.DefaultResult.Value
+		e.hasDefault = true
+	}
+	return e, nil
+}
+
+// Resolve resolves the compiled tree given a input
+func (e *Evaluator) Resolve(input interface{}) (interface{}, error) {
+	return e.ResolveContext(context.Background(), input)
+}
+
+// ResolveContext resolves the compiled tree given a input like ResolveTreeContext
+func (e *Evaluator) ResolveContext(ctx context.Context, input interface{}) (interface{}, error) {
+	res, err := e.root.resolve(ctx, nil, input, 0)
+	if err != nil {
+		if e.hasDefault && errors.Is(err, ErrNoMatchingChild) {
+			return e.defaultResult, nil
+		}
+		return nil, err
+	}
+	return res, nil
+}
+
+// Explain resolves the compiled tree given a input like ExplainTree
+func (e *Evaluator) Explain(input interface{}) (interface{}, *Trace, error) {
+	return e.ExplainContext(context.Background(), input)
+}
+
+// ExplainContext resolves the compiled tree given a input like ExplainTreeContext
+func (e *Evaluator) ExplainContext(ctx context.Context, input interface{}) (interface{}, *Trace, error) {
+	trace := &Trace{}
+	res, err := e.root.resolve(ctx, trace, input, 0)
+	if err != nil {
+		if !e.hasDefault || !errors.Is(err, ErrNoMatchingChild) {
+			return nil, trace, err
+		}
+		res = e.defaultResult
+		trace.DefaultResult = true
+	}
+	trace.Result = res
+	return res, trace, nil
+}
+
+// resolve resolves the node recording the visit in the trace when it is not nil
+func (n *compiledNode) resolve(ctx context.Context, trace *Trace, input interface{}, depth int) (interface{}, error) {
+	if err := ctx.Err(); err != nil {
+		return nil, err
+	}
+	step := trace.visitCompiled(n)
+	if n.leaf {
+		if !n.hasResult {
+			return nil, &InvalidLeafError{NodeID: n.id, Depth: depth}
+		}
+		return n.result, nil
+	}
+	var v reflect.Value
+	stepInput := input
+	for i, s := range n.steps {
+		if i != 0 {
+			stepInput = interfaceOf(v)
+		}
+		var err error
+		if v, err = s.extract(ctx, stepInput); err != nil {
+			return nil, &PreProcessError{NodeID: n.id, Depth: depth, PreProcessFn: s.fnName, Expression: s.expression, Value: stepInput, Err: err}
+		}
+	}
+	if step != nil {
+		step.setValue(interfaceOf(v))
+	}
+	for _, c := range n.children {
+		matched := c.match(v)
+		if step != nil {
+			step.compared(c.node.id, c.comparer, interfaceOf(v), c.valueToCompare, matched)
+		}
+		if matched {
+			return c.node.resolve(ctx, trace, input, depth+1)
+		}
+	}
+	if n.defaultChild != nil {
+		step.tookDefault()
+		return n.defaultChild.resolve(ctx, trace, input, depth+1)
+	}
+	return nil, &NoMatchingChildError{NodeID: n.id, Depth: depth, Value: interfaceOf(v)}
+}
+
+func compileNode(n *Node, numeric bool) *compiledNode {
+	c := &compiledNode{id: n.ID, leaf: len(n.Children) == 0,
+		preProcessFn: n.PreProcessFn.Name, pipeline: n.pipelineNames(), expression: n.Expression}
+	if n.Result != nil {
+		c.result = n.Result.Value
+		c.hasResult = true
+	}
+	if c.leaf {
+		return c
+	}
+	switch {
+	case n.Expression != "":
+		c.steps = []*compiledStep{compileExpressionStep(n)}
+	case len(n.Pipeline) == 0:
+		c.steps = []*compiledStep{compileStep(n.PreProcessFn, n.PreProcessArgs)}
+	}
+	for _, s := range n.Pipeline {
+		c.steps = append(c.steps, compileStep(s.PreProcessFn, s.PreProcessArgs))
+	}
+	for _, child := range n.Children {
+		if child.Default {
+			c.defaultChild = compileNode(child, numeric)
+			continue
+		}
+		b := interfaceValue(child.ValueToCompare)
+		c.children = append(c.children, &compiledChild{
+			node:           compileNode(child, numeric),
+			match:          compileMatcher(child.Comparer, b, numeric),
+			comparer:       child.Comparer,
+			valueToCompare: b,
+		})
+	}
+	return c
+}
+
+func compileStep(fn function.PreProcessFn, args []*value.Value) *compiledStep {
+	return &compiledStep{fnName: fn.Name, extract: compileExtractor(fn, value.GetValueInterfaces(args))}
+}
+
+// compileExpressionStep evaluates the expression of the node, usually already
+// compiled decoding the node or creating the tree
+func compileExpressionStep(n *Node) *compiledStep {
+	program, err := n.expression()
+	return &compiledStep{expression: n.Expression, extract: func(ctx context.Context, input interface{}) (reflect.Value, error) {
+		if err != nil {
+			return reflect.Value{}, err
+		}
+		res, err := program.EvalContext(ctx, input)
+		if err != nil {
+			return reflect.Value{}, err
+		}
+		return reflect.ValueOf(res), nil
+	}}
+}
+
+func compileExtractor(fn function.PreProcessFn, args []interface{}) extractor {
+	if fn.Empty() {
+		return func(ctx context.Context, input interface{}) (reflect.Value, error) {
+			return reflect.ValueOf(input), nil
+		}
+	}
+	generic := func(ctx context.Context, input interface{}) (reflect.Value, error) {
+		res, err := fn.Call(ctx, input, args...)
+		if err != nil {
+			return reflect.Value{}, err
+		}
+		return reflect.ValueOf(res), nil
+	}
+	if fn.ContextFunction != nil || len(args) == 0 {
+		return generic
+	}
+	name, ok := args[0].(string)
+	if !ok {
+		return generic
+	}
+	switch {
+	case sameFunction(fn.Function, function.GetStructAttribute) && len(args) == 1 && !strings.ContainsAny(name, ".["):
+		return (&fieldExtractor{name: name, fallback: generic}).extract
+	case sameFunction(fn.Function, function.CallStructMethod):
+		inputs := make([]reflect.Value, len(args)-1)
+		for i, a := range args[1:] {
+			inputs[i] = reflect.ValueOf(a)
+		}
+		return (&methodExtractor{name: name, inputs: inputs, fallback: generic}).extract
+	}
+	return generic
+}
+
+func sameFunction(a, b interface{}) bool {
+	return reflect.ValueOf(a).Pointer() == reflect.ValueOf(b).Pointer()
+}
+
+// fieldExtractor is GetStructAttribute with the field index cached per type
+type fieldExtractor struct {
+	name     string
+	indexes  sync.Map
+	fallback extractor
+}
+
+func (f *fieldExtractor) extract(ctx context.Context, input interface{}) (reflect.Value, error) {
+	v := reflect.Indirect(reflect.ValueOf(input))
+	if v.Kind() != reflect.Struct {
+		return f.fallback(ctx, input)
+	}
+	idx, ok := f.indexes.Load(v.Type())
+	if !ok {
+		index := -1
+		// fields promoted from embedded structs are left to the fallback
+		if field, found := v.Type().FieldByName(f.name); found && len(field.Index) == 1 && field.PkgPath == "" {
+			index = field.Index[0]
+		}
+		idx, _ = f.indexes.LoadOrStore(v.Type(), index)
+	}
+	if idx.(int) < 0 {
+		return f.fallback(ctx, input)
+	}
+	return elem(v.Field(idx.(int))), nil
+}
+
+// methodExtractor is CallStructMethod with the method cached per type
+type methodExtractor struct {
+	name     string
+	inputs   []reflect.Value
+	methods  sync.Map
+	fallback extractor
+}
+
+type cachedMethod struct {
+	index int
+	// errs are the positions of the results implementing error
+	errs []int
+}
+
+var errorInterface = reflect.TypeOf((*error)(nil)).Elem()
+
+func (m *methodExtractor) extract(ctx context.Context, input interface{}) (reflect.Value, error) {
+	if input == nil {
+		return m.fallback(ctx, input)
+	}
+	v := reflect.ValueOf(input)
+	cached, ok := m.methods.Load(v.Type())
+	if !ok {
+		var method *cachedMethod
+		if mt, found := v.Type().MethodByName(m.name); found && mt.Type.NumOut() != 0 {
+			method = &cachedMethod{index: mt.Index}
+			for i := 0; i < mt.Type.NumOut(); i++ {
+				if mt.Type.Out(i).Implements(errorInterface) {
+					method.errs = append(method.errs, i)
+				}
+			}
+		}
+		cached, _ = m.methods.LoadOrStore(v.Type(), method)
+	}
+	method := cached.(*cachedMethod)
+	if method == nil {
+		return m.fallback(ctx, input)
+	}
+	res := v.Method(method.index).Call(m.inputs)
+	for _, i := range method.errs {
+		if !res[i].IsNil() {
+			return reflect.Value{}, res[i].Interface().(error)
+		}
+	}
+	return elem(res[0]), nil
+}
+
+// elem returns the value stored in an interface value, the comparers receive
+// the dynamic value like when calling reflect.Value.Interface
+func elem(v reflect.Value) reflect.Value {
+	if v.Kind() == reflect.Interface {
+		return v.Elem()
+	}
+	return v
+}
+
+func interfaceOf(v reflect.Value) interface{} {
+	if !v.IsValid() {
+		return nil
+	}
+	return v.Interface()
+}
+
+var (
+	intType     = reflect.TypeOf(0)
+	int64Type   = reflect.TypeOf(int64(0))
+	float64Type = reflect.TypeOf(float64(0))
+	stringType  = reflect.TypeOf("")
+	boolType    = reflect.TypeOf(false)
+)
+
+// compileMatcher specializes the default comparers for the type of the value
+// to compare, any other comparer is called with the interface value. The
+// comparers with numeric coercion are not specialized.
+func compileMatcher(c Comparer, b interface{}, numeric bool) matcher {
+	if list, ok := b.([]interface{}); ok {
+		switch c.(type) {
+		case *compare.In, *compare.NotIn:
+			return setMatcher(c, list, numeric || numericMode(c))
+		}
+	}
+	if nc, ok := c.(compare.NumericComparer); ok && (numeric || numericMode(c)) {
+		return func(v reflect.Value) bool {
+			return nc.CompareNumeric(interfaceOf(v), b)
+		}
+	}
+	generic := func(v reflect.Value) bool {
+		return c.Compare(interfaceOf(v), b)
+	}
+	bType := reflect.TypeOf(b)
+	switch comp := c.(type) {
+	case *compare.Equal:
+		switch bType {
+		case intType, int64Type:
+			bInt := reflect.ValueOf(b).Int()
+			return func(v reflect.Value) bool {
+				return v.IsValid() && v.Type() == bType && v.Int() == bInt
+			}
+		case float64Type:
+			bFloat := b.(float64)
+			return func(v reflect.Value) bool {
+				return v.IsValid() && v.Type() == bType && v.Float() == bFloat
+			}
+		case stringType:
+			bString := b.(string)
+			return func(v reflect.Value) bool {
+				return v.IsValid() && v.Type() == bType && v.String() == bString
+			}
+		case boolType:
+			bBool := b.(bool)
+			return func(v reflect.Value) bool {
+				return v.IsValid() && v.Type() == bType && v.Bool() == bBool
+			}
+		}
+	case *compare.Greater:
+		return orderMatcher(bType, b, comp.Equal, 1, generic)
+	case *compare.Lesser:
+		return orderMatcher(bType, b, comp.Equal, -1, generic)
+	case *compare.Between:
+		bounds, ok := b.([]interface{})
+		if !ok || len(bounds) != 2 {
+			return generic
+		}
+		lower := compileMatcher(&compare.Greater{Equal: comp.LowerInclusive}, bounds[0], false)
+		upper := compileMatcher(&compare.Lesser{Equal: comp.UpperInclusive}, bounds[1], false)
+		return func(v reflect.Value) bool {
+			return lower(v) && upper(v)
+		}
+	case *compare.Regex:
+		if pattern, ok := b.(string); ok {
+			if re, err := comp.Regexp(pattern); err == nil {
+				return func(v reflect.Value) bool {
+					return v.IsValid() && v.Type() == stringType && re.MatchString(v.String())
+				}
+			}
+		}
+	}
+	return generic
+}
+
+// setMatcher looks up the value to compare of the In and NotIn comparers in a
+// set of the list, built once instead of searching the list on every call
+func setMatcher(c Comparer, list []interface{}, numeric bool) matcher {
+	set := compare.NewSet(list)
+	_, in := c.(*compare.In)
+	if numeric {
+		return func(v reflect.Value) bool {
+			return set.ContainsNumeric(interfaceOf(v)) == in
+		}
+	}
+	return func(v reflect.Value) bool {
+		return set.Contains(interfaceOf(v)) == in
+	}
+}
+
+// numericMode reports whether a default comparer has numeric coercion enabled
+func numericMode(c Comparer) bool {
+	switch comp := c.(type) {
+	case *compare.Equal:
+		return comp.Numeric
+	case *compare.Greater:
+		return comp.Numeric
+	case *compare.Lesser:
+		return comp.Numeric
+	case *compare.Between:
+		return comp.Numeric
+	case *compare.In:
+		return comp.Numeric
+	case *compare.NotIn:
+		return comp.Numeric
+	}
+	return false
+}
+
+// orderMatcher specializes Greater (sign 1) and Lesser (sign -1) comparers
+func orderMatcher(bType reflect.Type, b interface{}, equal bool, sign int, generic matcher) matcher {
+	switch bType {
+	case intType, int64Type:
+		bInt := reflect.ValueOf(b).Int()
+		return func(v reflect.Value) bool {
+			if !v.IsValid() || v.Type() != bType {
+				return false
+			}
+			a := v.Int()
+			return (equal && a == bInt) || (sign > 0 && a > bInt) || (sign < 0 && a < bInt)
+		}
+	case float64Type:
+		bFloat := b.(float64)
+		return func(v reflect.Value) bool {
+			if !v.IsValid() || v.Type() != bType {
+				return false
+			}
+			a := v.Float()
+			return (equal && a == bFloat) || (sign > 0 && a > bFloat) || (sign < 0 && a < bFloat)
+		}
+	case stringType:
+		bString := b.(string)
+		return func(v reflect.Value) bool {
+			if !v.IsValid() || v.Type() != bType {
+				return false
+			}
+			a := v.String()
+			return (equal && a == bString) || (sign > 0 && a > bString) || (sign < 0 && a < bString)
+		}
+	}
+	return generic
+}
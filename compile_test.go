@@ -0,0 +1,347 @@
+package ddt
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"github.com/sgrodriguez/ddt/compare"
+	"github.com/sgrodriguez/ddt/function"
+	"github.com/sgrodriguez/ddt/value"
+)
+
+type account struct {
+	Age     int
+	Balance float64
+	Country string
+	Active  bool
+	Plan    interface{}
+}
+
+func (a account) Minor() bool {
+	return a.Age < 18
+}
+
+func accountTree(t testing.TB) *Tree {
+	attr := func(name string) []*value.Value {
+		return []*value.Value{{Type: value.String, Value: name}}
+	}
+	getAttr := function.PreProcessFn{Function: function.GetStructAttribute, Name: "GetStructAttribute"}
+	leaf := func(id, parentID int, c Comparer, v *value.Value, res string) *Node {
+		return &Node{ID: id, ParentID: parentID, Comparer: c, ValueToCompare: v, Result: &value.Value{Type: value.String, Value: res}}
+	}
+	country := &Node{ID: 1, ParentID: 0, Comparer: &compare.Greater{}, ValueToCompare: &value.Value{Type: value.Int, Value: 30},
+		PreProcessFn: getAttr, PreProcessArgs: attr("Country"),
+		Children: []*Node{
+			leaf(3, 1, &compare.Equal{}, &value.Value{Type: value.String, Value: "UY"}, "old uruguayan"),
+			{ID: 4, ParentID: 1, Default: true, PreProcessFn: getAttr, PreProcessArgs: attr("Balance"), Children: []*Node{
+				leaf(7, 4, &compare.Greater{Equal: true}, &value.Value{Type: value.Float64, Value: 1000.0}, "old rich"),
+			}},
+		}}
+	young := &Node{ID: 2, ParentID: 0, Comparer: &compare.Lesser{Equal: true}, ValueToCompare: &value.Value{Type: value.Int, Value: 30},
+		PreProcessFn: getAttr, PreProcessArgs: attr("Active"),
+		Children: []*Node{
+			leaf(5, 2, &compare.Equal{}, &value.Value{Type: value.Bool, Value: true}, "young active"),
+			{ID: 6, ParentID: 2, Comparer: &compare.Equal{}, ValueToCompare: &value.Value{Type: value.Bool, Value: false},
+				PreProcessFn: function.PreProcessFn{Function: function.CallStructMethod, Name: "CallStructMethod"}, PreProcessArgs: attr("Minor"),
+				Children: []*Node{
+					leaf(8, 6, &compare.Equal{}, &value.Value{Type: value.Bool, Value: true}, "minor inactive"),
+					{ID: 9, ParentID: 6, Comparer: &compare.Equal{}, ValueToCompare: &value.Value{Type: value.Bool, Value: false},
+						PreProcessFn: getAttr, PreProcessArgs: attr("Plan"),
+						Children: []*Node{
+							leaf(10, 9, &compare.Equal{}, &value.Value{Type: value.String, Value: "gold"}, "gold inactive"),
+						}},
+				}},
+		}}
+	root := &Node{ID: 0, ParentID: -1, PreProcessFn: getAttr, PreProcessArgs: attr("Age"), Children: []*Node{country, young}}
+	tree, err := NewTree("accountTree", root)
+	require.NoError(t, err)
+	return tree
+}
+
+func TestCompile_SameResultsAsResolveTree(t *testing.T) {
+	trees := map[string]*Tree{"accountTree": accountTree(t), "userTree": userTree()}
+	inputs := []interface{}{
+		&account{Age: 40, Country: "UY"},
+		account{Age: 40, Country: "AR", Balance: 2000},
+		&account{Age: 40, Country: "AR", Balance: 10},
+		&account{Age: 20, Active: true},
+		&account{Age: 10},
+		&account{Age: 20, Plan: "gold"},
+		&account{Age: 20, Plan: 1},
+		newUser(11, "SANTIAGO", "LUCIA"),
+		newUser(11, "LUCIA", "SANTIAGO"),
+		newUser(25, "LUCIA", "SANTIAGO"),
+		newUser(65, "LUCIA", "SANTIAGO"),
+		&user{},
+		nil,
+		123,
+	}
+	for name, tree := range trees {
+		evaluator, err := Compile(tree)
+		require.NoError(t, err)
+		for _, input := range inputs {
+			expected, expectedErr := resolveNoPanic(func() (interface{}, error) { return ResolveTree(tree, input) })
+			actual, actualErr := resolveNoPanic(func() (interface{}, error) { return evaluator.Resolve(input) })
+			assert.Equal(t, expected, actual, "%s %#v", name, input)
+			assert.Equal(t, expectedErr, actualErr, "%s %#v", name, input)
+		}
+	}
+}
+
+// explainFixtures are trees covering every kind of node with inputs taking
+// every branch, including failing ones
+func explainFixtures(t *testing.T) map[string]struct {
+	tree   *Tree
+	inputs []interface{}
+} {
+	decode := func(data string, numeric bool) *Tree {
+		var tree Tree
+		require.NoError(t, json.Unmarshal([]byte(data), &tree))
+		tree.NumericCoercion = numeric
+		return &tree
+	}
+	accounts := []interface{}{
+		&account{Age: 40, Country: "UY"},
+		account{Age: 40, Country: "AR", Balance: 2000},
+		&account{Age: 40, Country: "AR", Balance: 10},
+		&account{Age: 20, Active: true},
+		&account{Age: 10},
+		&account{Age: 20, Plan: "gold"},
+		&account{Age: 20, Plan: 1},
+		nil,
+		123,
+	}
+	users := []interface{}{
+		newUser(11, "SANTIAGO", "LUCIA"),
+		newUser(11, "LUCIA", "SANTIAGO"),
+		newUser(25, "LUCIA", "SANTIAGO"),
+		newUser(65, "LUCIA", "SANTIAGO"),
+		&user{},
+		nil,
+	}
+	withDefault := userTree()
+	withDefault.DefaultResult = &value.Value{Type: value.String, Value: "fallback"}
+	return map[string]struct {
+		tree   *Tree
+		inputs []interface{}
+	}{
+		"accountTree":    {tree: accountTree(t), inputs: accounts},
+		"userTree":       {tree: userTree(), inputs: users},
+		"default result": {tree: withDefault, inputs: users},
+		"pipeline": {tree: pipelineTree(t), inputs: []interface{}{
+			&customer{Email: "Ana@Example.COM"}, &customer{Email: "bob@gmail.com"}, &customer{Email: "no-domain"},
+		}},
+		"expression": {tree: decode(expressionTreeJSON, false), inputs: []interface{}{
+			&subscriber{Age: 17, Months: 11},
+			&subscriber{Age: 30, Orders: []float64{50, 20, 20, 10}},
+			map[string]interface{}{"Age": 40, "Months": 1, "Orders": []int{100, 1, 1, 1}},
+			map[string]interface{}{"Age": 40},
+		}},
+		"library": {tree: decode(`{"name": "signup", "root": {
+			"pipeline": [
+				{"preProcessFnName": "GetMapValue", "preProcessFnArgs": [{"Value": "email", "Type": "string"}]},
+				{"preProcessFnName": "Lower"},
+				{"preProcessFnName": "Split", "preProcessFnArgs": [{"Value": "@", "Type": "string"}, {"Value": 1, "Type": "int"}]}
+			],
+			"children": [
+				{"comparer": {"type": "regex"}, "valueToCompare": {"Value": "^example\\.(com|org)$", "Type": "string"}, "result": {"Value": "internal", "Type": "string"}},
+				{"comparer": {"type": "in"}, "valueToCompare": {"Value": ["gmail.com", "yahoo.com"], "Type": "list"}, "result": {"Value": "free", "Type": "string"}},
+				{"default": true, "result": {"Value": "external", "Type": "string"}}
+			]}}`, false), inputs: []interface{}{
+			map[string]interface{}{"email": "Ana@Example.org"},
+			map[string]interface{}{"email": "bob@gmail.com"},
+			map[string]interface{}{"email": "bob@acme.com"},
+			map[string]interface{}{"email": "no-domain"},
+			map[string]interface{}{},
+		}},
+		"numeric coercion": {tree: decode(`{"name": "limits", "root": {"children": [
+			{"comparer": {"type": "gt"}, "valueToCompare": {"Value": 1000, "Type": "int64"}, "result": {"Value": "high", "Type": "string"}},
+			{"comparer": {"type": "in"}, "valueToCompare": {"Value": [0, 1, 2], "Type": "list"}, "result": {"Value": "tiny", "Type": "string"}},
+			{"comparer": {"type": "between", "lowerInclusive": true, "upperInclusive": true}, "valueToCompare": {"Value": [3, 1000], "Type": "list"}, "result": {"Value": "normal", "Type": "string"}}
+		]}}`, true), inputs: []interface{}{2000, uint8(1), 2.0, int32(500), 1000.5, -1, "text"}},
+		"composite": {tree: decode(`{"name": "access", "root": {
+			"preProcessFnName": "GetMapValue", "preProcessFnArgs": [{"Value": "age", "Type": "string"}],
+			"children": [
+				{"comparer": {"type": "and", "operands": [
+					{"comparer": {"type": "gt", "equal": true}, "value": {"Value": 18, "Type": "int"}},
+					{"comparer": {"type": "lt"}, "value": {"Value": 65, "Type": "int"}}
+				]}, "result": {"Value": "allowed", "Type": "string"}},
+				{"comparer": {"type": "not", "operand": {"comparer": {"type": "eq"}, "value": {"Value": 10, "Type": "int"}}},
+				 "result": {"Value": "denied", "Type": "string"}}
+			]}}`, false), inputs: []interface{}{
+			map[string]interface{}{"age": 30},
+			map[string]interface{}{"age": 70},
+			map[string]interface{}{"age": 10},
+		}},
+	}
+}
+
+func TestEvaluator_ExplainSameAsExplainTree(t *testing.T) {
+	explainNoPanic := func(fn func() (interface{}, *Trace, error)) (res interface{}, trace *Trace, err error) {
+		defer func() {
+			if r := recover(); r != nil {
+				err = errors.New("panic")
+			}
+		}()
+		return fn()
+	}
+	for name, fixture := range explainFixtures(t) {
+		tree := fixture.tree
+		evaluator, err := Compile(tree)
+		require.NoError(t, err, name)
+		for _, input := range fixture.inputs {
+			expected, expectedTrace, expectedErr := explainNoPanic(func() (interface{}, *Trace, error) { return ExplainTree(tree, input) })
+			actual, actualTrace, actualErr := explainNoPanic(func() (interface{}, *Trace, error) { return evaluator.Explain(input) })
+			assert.Equal(t, expected, actual, "%s %#v", name, input)
+			assert.Equal(t, expectedErr, actualErr, "%s %#v", name, input)
+			assert.Equal(t, expectedTrace, actualTrace, "%s %#v", name, input)
+
+			res, err := resolveNoPanic(func() (interface{}, error) { return evaluator.Resolve(input) })
+			assert.Equal(t, expected, res, "%s %#v", name, input)
+			assert.Equal(t, expectedErr, err, "%s %#v", name, input)
+		}
+	}
+}
+
+func TestCompile_StringComparers(t *testing.T) {
+	str := func(s string) *value.Value { return &value.Value{Type: value.String, Value: s} }
+	leaf := func(id int, c Comparer, v string) *Node {
+		return &Node{ID: id, ParentID: 0, Comparer: c, ValueToCompare: str(v), Result: str(v)}
+	}
+	root := &Node{ID: 0, ParentID: -1, PreProcessFn: function.PreProcessFn{Function: function.GetStructAttribute, Name: "GetStructAttribute"},
+		PreProcessArgs: []*value.Value{str("Country")},
+		Children: []*Node{
+			leaf(1, &compare.Regex{}, `^U[YS]$`),
+			leaf(2, &compare.EqualFold{}, "ar"),
+			leaf(3, &compare.Prefix{}, "B"),
+			leaf(4, &compare.Greater{Equal: true}, "M"),
+			leaf(5, &compare.Lesser{}, "C"),
+		}}
+	tree, err := NewTree("countries", root)
+	require.NoError(t, err)
+	evaluator, err := Compile(tree)
+	require.NoError(t, err)
+	for _, country := range []string{"UY", "US", "Ar", "BR", "PY", "AA", "CL", "uy"} {
+		expected, expectedErr := ResolveTree(tree, &account{Country: country})
+		actual, actualErr := evaluator.Resolve(&account{Country: country})
+		assert.Equal(t, expected, actual, country)
+		assert.Equal(t, expectedErr, actualErr, country)
+	}
+	res, err := evaluator.Resolve(&account{Country: "PY"})
+	require.NoError(t, err)
+	assert.Equal(t, "M", res)
+}
+
+func resolveNoPanic(fn func() (interface{}, error)) (res interface{}, err error) {
+	defer func() {
+		if r := recover(); r != nil {
+			err = errors.New("panic")
+		}
+	}()
+	return fn()
+}
+
+func TestCompile_Errors(t *testing.T) {
+	tree := accountTree(t)
+	evaluator, err := Compile(tree)
+	require.NoError(t, err)
+	_, err = evaluator.Resolve(&account{Age: 20, Plan: "silver"})
+	var noMatchErr *NoMatchingChildError
+	require.True(t, errors.As(err, &noMatchErr))
+	assert.Equal(t, &NoMatchingChildError{NodeID: 9, Depth: 3, Value: "silver"}, noMatchErr)
+
+	ctx, cancel := context.WithCancel(context.Background())
+	cancel()
+	_, err = evaluator.ResolveContext(ctx, &account{})
+	assert.True(t, errors.Is(err, context.Canceled))
+
+	tree.DefaultResult = &value.Value{Type: value.String, Value: "fallback"}
+	evaluator, err = Compile(tree)
+	require.NoError(t, err)
+	res, err := evaluator.Resolve(&account{Age: 20, Plan: "silver"})
+	require.NoError(t, err)
+	assert.Equal(t, "fallback", res)
+
+	tree.Root.Children[0].Comparer = nil
+	_, err = Compile(tree)
+	var validationErr *ValidationError
+	assert.True(t, errors.As(err, &validationErr))
+}
+
+func TestCompile_NoAllocations(t *testing.T) {
+	evaluator, err := Compile(accountTree(t))
+	require.NoError(t, err)
+	input := &account{Age: 40, Country: "AR", Balance: 2000}
+	allocs := testing.AllocsPerRun(100, func() {
+		_, _ = evaluator.Resolve(input)
+	})
+	assert.Equal(t, 0.0, allocs)
+}
+
+func BenchmarkResolveTree_AccountTree(b *testing.B) {
+	tree := accountTree(b)
+	input := &account{Age: 40, Country: "AR", Balance: 2000}
+	b.ReportAllocs()
+	for i := 0; i < b.N; i++ {
+		_, _ = ResolveTree(tree, input)
+	}
+}
+
+func BenchmarkEvaluator_AccountTree(b *testing.B) {
+	evaluator, _ := Compile(accountTree(b))
+	input := &account{Age: 40, Country: "AR", Balance: 2000}
+	b.ReportAllocs()
+	for i := 0; i < b.N; i++ {
+		_, _ = evaluator.Resolve(input)
+	}
+}
+
+func BenchmarkResolveTree_UserTree(b *testing.B) {
+	tree := userTree()
+	input := newUser(25, "LUCIA", "SANTIAGO")
+	b.ReportAllocs()
+	for i := 0; i < b.N; i++ {
+		_, _ = ResolveTree(tree, input)
+	}
+}
+
+func BenchmarkEvaluator_UserTree(b *testing.B) {
+	evaluator, _ := Compile(userTree())
+	input := newUser(25, "LUCIA", "SANTIAGO")
+	b.ReportAllocs()
+	for i := 0; i < b.N; i++ {
+		_, _ = evaluator.Resolve(input)
+	}
+}
+
+func TestCompile_SetComparers(t *testing.T) {
+	ages := make([]interface{}, 20)
+	for i := range ages {
+		ages[i] = int64(i * 5)
+	}
+	str := func(s string) *value.Value { return &value.Value{Type: value.String, Value: s} }
+	root := &Node{ID: 0, ParentID: -1, PreProcessFn: function.PreProcessFn{Function: function.GetStructAttribute, Name: "GetStructAttribute"},
+		PreProcessArgs: []*value.Value{str("Age")},
+		Children: []*Node{
+			{ID: 1, ParentID: 0, Comparer: &compare.In{Numeric: true}, ValueToCompare: &value.Value{Type: value.List, Value: ages}, Result: str("round")},
+			{ID: 2, ParentID: 0, Comparer: &compare.NotIn{}, ValueToCompare: &value.Value{Type: value.List, Value: ages}, Result: str("other")},
+		}}
+	tree, err := NewTree("ages", root)
+	require.NoError(t, err)
+	evaluator, err := Compile(tree)
+	require.NoError(t, err)
+	for _, age := range []int{0, 5, 7, 95, 96, 100} {
+		expected, expectedErr := ResolveTree(tree, &account{Age: age})
+		actual, actualErr := evaluator.Resolve(&account{Age: age})
+		assert.Equal(t, expected, actual, age)
+		assert.Equal(t, expectedErr, actualErr, age)
+	}
+	res, err := evaluator.Resolve(&account{Age: 35})
+	require.NoError(t, err)
+	assert.Equal(t, "round", res)
+}
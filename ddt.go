@@ -1,40 +1,150 @@
 package ddt
 
 import (
+	"context"
 	"errors"
 
 	"github.com/sgrodriguez/ddt/function"
+	"github.com/sgrodriguez/ddt/value"
 )
 
 // Tree Type
 type Tree struct {
 	Root      *Node                            `json:"-"`
 	Functions map[string]function.PreProcessFn `json:"-"`
-	Name      string                           `json:"name"`
+	// Comparers are the comparer factories used decoding the tree by type,
+	// they take precedence over the ones registered with RegisterComparer.
+	Comparers map[string]ComparerFactory `json:"-"`
+	Name      string                     `json:"name"`
+	// DefaultResult is returned when a node has no child matching the input
+	// and no default child, instead of failing the resolution.
+	DefaultResult *value.Value `json:"defaultResult,omitempty"`
+	// NumericCoercion compares numbers of different types by value with every
+	// comparer implementing compare.NumericComparer, so an int input matches
+	// an int64 value to compare.
+	NumericCoercion bool `json:"numericCoercion,omitempty"`
 }
 
-// NewTree creates a tree
+// NewTree creates a tree, the whole tree is validated before returning it and
+// the expressions and regex patterns of its nodes are compiled. A nil root creates an empty
+// tree with the functions to decode a tree into.
 func NewTree(name string, rootNode *Node, fn ...function.PreProcessFn) (*Tree, error) {
-	if !isValidRootNode(rootNode) {
-		return nil, errors.New("invalid root node")
+	tree := &Tree{Name: name, Functions: addNewPreProcessFn(fn), Root: rootNode}
+	if rootNode == nil {
+		return tree, nil
 	}
-	return &Tree{Name: name, Functions: addNewPreProcessFn(fn), Root: rootNode}, nil
+	if err := tree.Validate(); err != nil {
+		return nil, err
+	}
+	for _, n := range getAllNodes(rootNode) {
+		n.precompile()
+	}
+	return tree, nil
 }
 
 // ResolveTree resolves a tree given a input
 func ResolveTree(t *Tree, input interface{}) (interface{}, error) {
-	return t.Root.NextNode(input)
+	return ResolveTreeContext(context.Background(), t, input)
+}
+
+// ResolveTreeContext resolves a tree given a input, the context is checked
+// before visiting each node and given to the context aware pre process
+// functions. When the context is done its error is returned. A tree without
+// root fails with ErrMissingRoot.
+func ResolveTreeContext(ctx context.Context, t *Tree, input interface{}) (interface{}, error) {
+	if t.Root == nil {
+		return nil, ErrMissingRoot
+	}
+	res, err := t.Root.resolve(&resolution{ctx: ctx, numeric: t.NumericCoercion}, input, 0)
+	if err != nil {
+		return t.defaultResult(err, nil)
+	}
+	return res, nil
+}
+
+// ExplainTree resolves a tree given a input like ResolveTree, also returning
+// the trace of the resolution. The trace is returned even when the
+// resolution fails, showing the path followed until the failure.
+func ExplainTree(t *Tree, input interface{}) (interface{}, *Trace, error) {
+	return ExplainTreeContext(context.Background(), t, input)
+}
+
+// ExplainTreeContext is like ExplainTree using the given context like
+// ResolveTreeContext
+func ExplainTreeContext(ctx context.Context, t *Tree, input interface{}) (interface{}, *Trace, error) {
+	trace := &Trace{}
+	if t.Root == nil {
+		return nil, trace, ErrMissingRoot
+	}
+	res, err := t.Root.resolve(&resolution{ctx: ctx, trace: trace, numeric: t.NumericCoercion}, input, 0)
+	if err != nil {
+		res, err = t.defaultResult(err, trace)
+		if err != nil {
+			return nil, trace, err
+		}
+	}
+	trace.Result = res
+	return res, trace, nil
 }
 
-// DefaultFns default function
+// defaultResult returns the default result of the tree when err is caused by
+// a node without matching children, otherwise err is returned.
+func (t *Tree) defaultResult(err error, trace *Trace) (interface{}, error) {
+	if t.DefaultResult == nil || !errors.Is(err, ErrNoMatchingChild) {
+		return nil, err
+	}
+	if trace != nil {
+		trace.DefaultResult = true
+	}
+	return t.DefaultResult.Value, nil
+}
+
+// DefaultFns are the pre process functions available in every tree
 var DefaultFns = []function.PreProcessFn{
 	{Function: function.CallStructMethod, Name: "CallStructMethod"},
 	{Function: function.GetStructAttribute, Name: "GetStructAttribute"},
+	{Function: function.GetMapValue, Name: "GetMapValue"},
+	// strings
+	{Function: function.Lower, Name: "Lower"},
+	{Function: function.Upper, Name: "Upper"},
+	{Function: function.Trim, Name: "Trim"},
+	{Function: function.Substring, Name: "Substring"},
+	{Function: function.Length, Name: "Length"},
+	{Function: function.Split, Name: "Split"},
+	// numbers
+	{Function: function.Abs, Name: "Abs"},
+	{Function: function.Round, Name: "Round"},
+	{Function: function.Floor, Name: "Floor"},
+	{Function: function.Ceil, Name: "Ceil"},
+	{Function: function.Add, Name: "Add"},
+	{Function: function.Sub, Name: "Sub"},
+	{Function: function.Mul, Name: "Mul"},
+	{Function: function.Div, Name: "Div"},
+	// dates
+	{Function: function.Age, Name: "Age"},
+	{Function: function.DayOfWeek, Name: "DayOfWeek"},
+	{Function: function.Hour, Name: "Hour"},
+	// collections
+	{Function: function.Len, Name: "Len"},
+	{Function: function.Contains, Name: "Contains"},
+	{Function: function.Sum, Name: "Sum"},
+	{Function: function.Min, Name: "Min"},
+	{Function: function.Max, Name: "Max"},
+	// casts
+	{Function: function.ToInt, Name: "ToInt"},
+	{Function: function.ToInt64, Name: "ToInt64"},
+	{Function: function.ToFloat64, Name: "ToFloat64"},
+	{Function: function.ToString, Name: "ToString"},
+	{Function: function.ToBool, Name: "ToBool"},
 }
 
+// addNewPreProcessFn returns the functions of a tree by name, the given
+// functions replace the default ones with the same name
 func addNewPreProcessFn(newPreProcessFn []function.PreProcessFn) map[string]function.PreProcessFn {
-	newPreProcessFn = append(newPreProcessFn, DefaultFns...)
 	res := map[string]function.PreProcessFn{}
+	for _, p := range DefaultFns {
+		res[p.Name] = p
+	}
 	for _, p := range newPreProcessFn {
 		res[p.Name] = p
 	}
@@ -1,9 +1,14 @@
 package ddt
 
 import (
+	"context"
 	"encoding/json"
 	"errors"
+	"math"
+	"strings"
+	"sync"
 	"testing"
+	"time"
 
 	"github.com/stretchr/testify/assert"
 	"github.com/stretchr/testify/require"
@@ -169,7 +174,7 @@ func TestResolveTree_UserTree(t *testing.T) {
 	b, err := json.Marshal(userTree)
 	require.NoError(t, err)
 
-	treeFromJSON, err := NewTree("treeFromJSON", &Node{ID: 0, ParentID: -1})
+	treeFromJSON, err := NewTree("treeFromJSON", nil)
 	err = json.Unmarshal(b, treeFromJSON)
 	require.NoError(t, err)
 
@@ -196,33 +201,53 @@ func TestResolveTree_UserTree(t *testing.T) {
 	t.Run("handle empty/invalid user", func(t *testing.T) {
 		_, err = ResolveTree(userTree, &user{})
 		require.Error(t, err)
-		assert.EqualError(t, err, "value not found when comparing with all children nodes")
+		assert.EqualError(t, err, "node 1 (depth 1): value not found when comparing with all children nodes")
+		assert.True(t, errors.Is(err, ErrNoMatchingChild))
+		var noMatchErr *NoMatchingChildError
+		require.True(t, errors.As(err, &noMatchErr))
+		assert.Equal(t, &NoMatchingChildError{NodeID: 1, Depth: 1, Value: " "}, noMatchErr)
 		_, err = ResolveTree(userTree, nil)
 		require.Error(t, err)
-		assert.EqualError(t, err, "callStructMethod invalid methods args")
+		assert.EqualError(t, err, `node 0 (depth 0): pre process function "CallStructMethod" failed: callStructMethod invalid methods args`)
+		assert.True(t, errors.Is(err, ErrPreProcess))
+		assert.True(t, errors.Is(err, function.ErrInvalidMethodArgs))
 		_, err = ResolveTree(userTree, 123)
 		require.Error(t, err)
-		assert.EqualError(t, err, "callStructMethod invalid methods args")
-
+		var preProcessErr *PreProcessError
+		require.True(t, errors.As(err, &preProcessErr))
+		assert.Equal(t, 0, preProcessErr.NodeID)
+		assert.Equal(t, 123, preProcessErr.Value)
+		assert.True(t, errors.Is(err, function.ErrInvalidMethodArgs))
 	})
 	t.Run("handling method error", func(t *testing.T) {
 		node1 := userTree.Root.Children[0]
 		node1.PreProcessArgs = []*value.Value{{Type: value.String, Value: "ReturnErr"}}
 		_, err = ResolveTree(userTree, &user{Age: 11})
 		require.Error(t, err)
-		assert.EqualError(t, err, "always error")
+		assert.EqualError(t, err, `node 1 (depth 1): pre process function "CallStructMethod" failed: always error`)
+		assert.EqualError(t, errors.Unwrap(err), "always error")
 	})
 	t.Run("invalid method name", func(t *testing.T) {
 		node1 := userTree.Root.Children[0]
 		node1.PreProcessArgs = []*value.Value{{Type: value.String, Value: "ASD"}}
 		_, err = ResolveTree(userTree, &user{Age: 11})
-		assert.EqualError(t, err, "callStructMethod invalid methods args")
+		assert.True(t, errors.Is(err, function.ErrInvalidMethodArgs))
 	})
 	t.Run("invalid struct attribute name", func(t *testing.T) {
 		node2 := userTree.Root.Children[1]
 		node2.PreProcessArgs = []*value.Value{{Type: value.String, Value: "ASD"}}
 		_, err = ResolveTree(userTree, &user{Age: 30})
-		assert.EqualError(t, err, "getStructAttribute invalid struct attribute")
+		assert.EqualError(t, err, `node 2 (depth 1): pre process function "GetStructAttribute" failed: getStructAttribute invalid struct attribute: ASD`)
+		assert.True(t, errors.Is(err, function.ErrInvalidStructAttribute))
+	})
+	t.Run("leaf without result", func(t *testing.T) {
+		node3 := userTree.Root.Children[0].Children[0]
+		userTree.Root.Children[0].PreProcessArgs = []*value.Value{{Type: value.String, Value: "FullName"}}
+		node3.Result = nil
+		_, err = ResolveTree(userTree, newUser(11, "SANTIAGO", "LUCIA"))
+		assert.EqualError(t, err, "node 3 (depth 2): leaf node without result")
+		assert.True(t, errors.Is(err, ErrInvalidLeaf))
+		assert.False(t, errors.Is(err, ErrNoMatchingChild))
 	})
 }
 
@@ -315,6 +340,16 @@ func TestMarshalComparator(t *testing.T) {
 		"marshal greater or equal": {input: &compare.Greater{Equal: true}, expected: `{"equal":true, "type":"gt"}`},
 		"marshal lesser":           {input: &compare.Lesser{}, expected: `{"equal":false, "type":"lt"}`},
 		"marshal lesser or equal":  {input: &compare.Lesser{Equal: true}, expected: `{"equal":true, "type":"lt"}`},
+		"marshal prefix":           {input: &compare.Prefix{}, expected: `{"type":"prefix"}`},
+		"marshal suffix":           {input: &compare.Suffix{}, expected: `{"type":"suffix"}`},
+		"marshal contains":         {input: &compare.Contains{}, expected: `{"type":"contains"}`},
+		"marshal equal fold":       {input: &compare.EqualFold{}, expected: `{"type":"eqFold"}`},
+		"marshal regex":            {input: &compare.Regex{}, expected: `{"type":"regex"}`},
+		"marshal in":               {input: &compare.In{}, expected: `{"type":"in"}`},
+		"marshal not in":           {input: &compare.NotIn{}, expected: `{"type":"notIn"}`},
+		"marshal between":          {input: &compare.Between{UpperInclusive: true}, expected: `{"type":"between","lowerInclusive":false,"upperInclusive":true}`},
+		"marshal numeric equal":    {input: &compare.Equal{Numeric: true}, expected: `{"type":"eq","numeric":true}`},
+		"marshal numeric in":       {input: &compare.In{Numeric: true}, expected: `{"type":"in","numeric":true}`},
 	}
 	for name, tst := range tests {
 		t.Run(name, func(t *testing.T) {
@@ -335,6 +370,24 @@ func TestUnmarshallComparator(t *testing.T) {
 		"unmarshal greater or equal": {expected: &compare.Greater{Equal: true}, input: `{"equal":true, "type":"gt"}`},
 		"unmarshal lesser":           {expected: &compare.Lesser{}, input: `{"equal":false, "type":"lt"}`},
 		"unmarshal lesser or equal":  {expected: &compare.Lesser{Equal: true}, input: `{"equal":true, "type":"lt"}`},
+		"unmarshal prefix":           {expected: &compare.Prefix{}, input: `{"type":"prefix"}`},
+		"unmarshal suffix":           {expected: &compare.Suffix{}, input: `{"type":"suffix"}`},
+		"unmarshal contains":         {expected: &compare.Contains{}, input: `{"type":"contains"}`},
+		"unmarshal equal fold":       {expected: &compare.EqualFold{}, input: `{"type":"eqFold"}`},
+		"unmarshal regex":            {expected: &compare.Regex{}, input: `{"type":"regex"}`},
+		"unmarshal in":               {expected: &compare.In{}, input: `{"type":"in"}`},
+		"unmarshal not in":           {expected: &compare.NotIn{}, input: `{"type":"notIn"}`},
+		"unmarshal between":          {expected: &compare.Between{LowerInclusive: true}, input: `{"type":"between","lowerInclusive":true}`},
+		"unmarshal numeric greater":  {expected: &compare.Greater{Equal: true, Numeric: true}, input: `{"type":"gt","equal":true,"numeric":true}`},
+		"unmarshal numeric not in":   {expected: &compare.NotIn{Numeric: true}, input: `{"type":"notIn","numeric":true}`},
+		"unmarshal and": {
+			expected: &compare.And{Operands: []*compare.Operand{
+				{Comparer: &compare.Greater{}, Value: &value.Value{Type: value.Int, Value: 18}},
+				{Comparer: &compare.Not{Operand: &compare.Operand{Comparer: &compare.Equal{}, Value: &value.Value{Type: value.String, Value: "x"}}}},
+			}},
+			input: `{"type":"and","operands":[{"comparer":{"type":"gt"},"value":{"Value":18,"Type":"int"}},{"comparer":{"type":"not","operand":{"comparer":{"type":"eq"},"value":{"Value":"x","Type":"string"}}}}]}`,
+		},
+		"unmarshal or": {expected: &compare.Or{Operands: []*compare.Operand{}}, input: `{"type":"or","operands":[]}`},
 	}
 	for name, tst := range tests {
 		t.Run(name, func(t *testing.T) {
@@ -348,6 +401,9 @@ func TestUnmarshallComparator(t *testing.T) {
 	}{
 		"unmarshal unknown type":      {input: `{"type":"asd"}`},
 		"unmarshal invalid json type": {input: `{"equal":"asd"}`},
+		"unmarshal invalid operand":   {input: `{"type":"and","operands":[{"comparer":{"type":"asd"}}]}`},
+		"unmarshal missing operand":   {input: `{"type":"not"}`},
+		"unmarshal operand no comp":   {input: `{"type":"or","operands":[{"value":{"Value":1,"Type":"int"}}]}`},
 	}
 	for name, tst := range testsError {
 		t.Run(name, func(t *testing.T) {
@@ -356,3 +412,422 @@ func TestUnmarshallComparator(t *testing.T) {
 		})
 	}
 }
+
+func TestResolveTree_DefaultBranch(t *testing.T) {
+	treeJSON := []byte(`{"name":"defaultTree","nodes":[{"id":0,"parentId":-1},{"id":1,"parentId":0,"comparer":{"type":"gt","equal":false},"valueToCompare":{"Value":60,"Type":"int"},"result":{"Value":"senior","Type":"string"}},{"id":2,"parentId":0,"comparer":{"type":"lt","equal":false},"valueToCompare":{"Value":18,"Type":"int"},"result":{"Value":"minor","Type":"string"}},{"id":3,"parentId":0,"default":true,"result":{"Value":"adult","Type":"string"}}]}`)
+	var tree Tree
+	require.NoError(t, json.Unmarshal(treeJSON, &tree))
+	testCases := map[string]struct {
+		input    int
+		expected string
+	}{
+		"given 70 expect senior":          {input: 70, expected: "senior"},
+		"given 10 expect minor":           {input: 10, expected: "minor"},
+		"given 30 expect default adult":   {input: 30, expected: "adult"},
+		"given 60 expect default adult":   {input: 60, expected: "adult"},
+		"given 18 expected default adult": {input: 18, expected: "adult"},
+	}
+	for name, tc := range testCases {
+		t.Run(name, func(t *testing.T) {
+			actual, err := ResolveTree(&tree, tc.input)
+			require.NoError(t, err)
+			assert.Equal(t, tc.expected, actual)
+		})
+	}
+	_, trace, err := ExplainTree(&tree, 30)
+	require.NoError(t, err)
+	assert.True(t, trace.Steps[0].Default)
+	assert.Equal(t, []int{0, 3}, trace.VisitedIDs())
+
+	b, err := json.Marshal(&tree)
+	require.NoError(t, err)
+	assert.Contains(t, string(b), `"default":true`)
+
+	t.Run("next node", func(t *testing.T) {
+		res, err := tree.Root.NextNode(30)
+		require.NoError(t, err)
+		assert.Equal(t, "adult", res)
+		res, err = tree.Root.Children[0].NextNode(30)
+		require.NoError(t, err)
+		assert.Equal(t, "senior", res, "a leaf resolves to its result")
+	})
+	t.Run("more than one default child", func(t *testing.T) {
+		root := &Node{ID: 0, ParentID: -1, Children: []*Node{
+			{ID: 1, ParentID: 0, Default: true, Result: &value.Value{Type: value.Int, Value: 1}},
+			{ID: 2, ParentID: 0, Default: true, Result: &value.Value{Type: value.Int, Value: 2}},
+		}}
+		_, err := NewTree("invalid", root)
+		assert.EqualError(t, err, "invalid tree: node 0: more than one default child")
+	})
+}
+
+func TestResolveTree_DefaultResult(t *testing.T) {
+	ut := userTree()
+	ut.DefaultResult = &value.Value{Type: value.String, Value: "fallback"}
+	result, err := ResolveTree(ut, &user{})
+	require.NoError(t, err)
+	assert.Equal(t, "fallback", result)
+
+	result, trace, err := ExplainTree(ut, &user{})
+	require.NoError(t, err)
+	assert.Equal(t, "fallback", result)
+	assert.True(t, trace.DefaultResult)
+
+	// errors not caused by a missing match are still returned
+	_, err = ResolveTree(ut, nil)
+	assert.True(t, errors.Is(err, function.ErrInvalidMethodArgs))
+
+	b, err := json.Marshal(ut)
+	require.NoError(t, err)
+	var decoded Tree
+	require.NoError(t, json.Unmarshal(b, &decoded))
+	assert.Equal(t, ut.DefaultResult, decoded.DefaultResult)
+}
+
+func TestResolveTreeContext(t *testing.T) {
+	slowFn := function.PreProcessFn{
+		Name: "Slow",
+		ContextFunction: func(ctx context.Context, str interface{}, args ...interface{}) (interface{}, error) {
+			select {
+			case <-ctx.Done():
+				return nil, ctx.Err()
+			case <-time.After(time.Second):
+				return str, nil
+			}
+		},
+	}
+	root := &Node{ID: 0, ParentID: -1, PreProcessFn: slowFn, Children: []*Node{
+		{ID: 1, ParentID: 0, Comparer: &compare.Equal{}, ValueToCompare: &value.Value{Type: value.Int, Value: 1}, Result: &value.Value{Type: value.String, Value: "one"}},
+	}}
+	tree, err := NewTree("slowTree", root, slowFn)
+	require.NoError(t, err)
+
+	t.Run("deadline exceeded while pre processing", func(t *testing.T) {
+		ctx, cancel := context.WithTimeout(context.Background(), 10*time.Millisecond)
+		defer cancel()
+		_, err := ResolveTreeContext(ctx, tree, 1)
+		assert.True(t, errors.Is(err, context.DeadlineExceeded))
+		assert.True(t, errors.Is(err, ErrPreProcess))
+	})
+	t.Run("canceled before resolving", func(t *testing.T) {
+		ctx, cancel := context.WithCancel(context.Background())
+		cancel()
+		_, trace, err := ExplainTreeContext(ctx, tree, 1)
+		assert.True(t, errors.Is(err, context.Canceled))
+		assert.Empty(t, trace.Steps)
+	})
+	t.Run("context function decoded from json", func(t *testing.T) {
+		b, err := json.Marshal(tree)
+		require.NoError(t, err)
+		decoded, err := NewTree("decoded", nil, slowFn)
+		require.NoError(t, err)
+		require.NoError(t, json.Unmarshal(b, decoded))
+		ctx, cancel := context.WithCancel(context.Background())
+		go func() {
+			time.Sleep(10 * time.Millisecond)
+			cancel()
+		}()
+		_, err = ResolveTreeContext(ctx, decoded, 1)
+		assert.True(t, errors.Is(err, context.Canceled))
+	})
+}
+
+func TestResolveTree_EmptyTree(t *testing.T) {
+	tree, err := NewTree("empty", nil)
+	require.NoError(t, err)
+	_, err = ResolveTree(tree, 1)
+	assert.Equal(t, ErrMissingRoot, err)
+	_, trace, err := ExplainTree(tree, 1)
+	assert.Equal(t, ErrMissingRoot, err)
+	assert.Empty(t, trace.Steps)
+	_, err = tree.Root.NextNode(1)
+	assert.Equal(t, ErrMissingRoot, err)
+	b, err := json.Marshal(tree)
+	require.NoError(t, err)
+	assert.JSONEq(t, `{"name": "empty", "nodes": []}`, string(b))
+}
+
+func TestResolveTree_MapInput(t *testing.T) {
+	nested := []byte(`{"name": "orders", "root": {
+		"preProcessFnName": "GetMapValue", "preProcessFnArgs": [{"Value": "order.items[0].price", "Type": "string"}],
+		"children": [
+			{"comparer": {"type": "gt", "equal": true}, "valueToCompare": {"Value": 100, "Type": "int"},
+			 "preProcessFnName": "GetMapValue", "preProcessFnArgs": [{"Value": "order.total", "Type": "string"}, {"Value": "float64", "Type": "string"}],
+			 "children": [
+				{"comparer": {"type": "gt"}, "valueToCompare": {"Value": 500, "Type": "float64"}, "result": {"Value": "manual review", "Type": "string"}},
+				{"default": true, "result": {"Value": "approve", "Type": "string"}}
+			 ]},
+			{"default": true, "result": {"Value": "cheap", "Type": "string"}}
+		]}}`)
+	var tree Tree
+	require.NoError(t, json.Unmarshal(nested, &tree))
+	tests := map[string]struct {
+		input    string
+		expected interface{}
+	}{
+		"cheap first item":   {input: `{"order": {"total": 900, "items": [{"price": 10}]}}`, expected: "cheap"},
+		"big integer total":  {input: `{"order": {"total": 900, "items": [{"price": 100}]}}`, expected: "manual review"},
+		"small float total":  {input: `{"order": {"total": 120.5, "items": [{"price": 120}]}}`, expected: "approve"},
+		"first item is used": {input: `{"order": {"total": 501, "items": [{"price": 250}, {"price": 1}]}}`, expected: "manual review"},
+	}
+	for name, tc := range tests {
+		t.Run(name, func(t *testing.T) {
+			var input interface{}
+			dec := json.NewDecoder(strings.NewReader(tc.input))
+			dec.UseNumber()
+			require.NoError(t, dec.Decode(&input))
+			res, err := ResolveTree(&tree, input)
+			require.NoError(t, err)
+			assert.Equal(t, tc.expected, res)
+		})
+	}
+	t.Run("missing key", func(t *testing.T) {
+		_, err := ResolveTree(&tree, map[string]interface{}{"order": map[string]interface{}{}})
+		assert.True(t, errors.Is(err, ErrPreProcess))
+		assert.True(t, errors.Is(err, function.ErrMapKeyNotFound))
+	})
+}
+
+func TestDefaultFns_Library(t *testing.T) {
+	nested := []byte(`{"name": "signup", "root": {
+		"pipeline": [
+			{"preProcessFnName": "GetMapValue", "preProcessFnArgs": [{"Value": "email", "Type": "string"}]},
+			{"preProcessFnName": "Trim"},
+			{"preProcessFnName": "Lower"},
+			{"preProcessFnName": "Split", "preProcessFnArgs": [{"Value": "@", "Type": "string"}, {"Value": 1, "Type": "int"}]}
+		],
+		"children": [
+			{"comparer": {"type": "eq"}, "valueToCompare": {"Value": "example.com", "Type": "string"},
+			 "pipeline": [
+				{"preProcessFnName": "GetMapValue", "preProcessFnArgs": [{"Value": "scores", "Type": "string"}]},
+				{"preProcessFnName": "Sum"},
+				{"preProcessFnName": "Div", "preProcessFnArgs": [{"Value": 2, "Type": "int"}]}
+			 ],
+			 "children": [
+				{"comparer": {"type": "gt"}, "valueToCompare": {"Value": 5, "Type": "float64"}, "result": {"Value": "high", "Type": "string"}},
+				{"default": true, "result": {"Value": "low", "Type": "string"}}
+			 ]},
+			{"default": true, "result": {"Value": "external", "Type": "string"}}
+		]}}`)
+	var tree Tree
+	require.NoError(t, json.Unmarshal(nested, &tree))
+	tests := map[string]struct {
+		input    map[string]interface{}
+		expected interface{}
+	}{
+		"high":     {input: map[string]interface{}{"email": " Ana@Example.com", "scores": []interface{}{4, 7}}, expected: "high"},
+		"low":      {input: map[string]interface{}{"email": "bob@example.com", "scores": []interface{}{1, 2.5}}, expected: "low"},
+		"external": {input: map[string]interface{}{"email": "bob@gmail.com"}, expected: "external"},
+	}
+	for name, tc := range tests {
+		t.Run(name, func(t *testing.T) {
+			res, err := ResolveTree(&tree, tc.input)
+			require.NoError(t, err)
+			assert.Equal(t, tc.expected, res)
+		})
+	}
+	t.Run("invalid input", func(t *testing.T) {
+		_, err := ResolveTree(&tree, map[string]interface{}{"email": "no-domain"})
+		assert.True(t, errors.Is(err, function.ErrInvalidInput))
+		assert.EqualError(t, err, `node 0 (depth 0): pre process function "Split" failed: split invalid input: "no-domain" has 1 parts, index 1 out of range`)
+	})
+	t.Run("shared input", func(t *testing.T) {
+		var input interface{}
+		dec := json.NewDecoder(strings.NewReader(`{"email": "ana@example.com", "scores": [4, 7.5]}`))
+		dec.UseNumber()
+		require.NoError(t, dec.Decode(&input))
+		var wg sync.WaitGroup
+		for i := 0; i < 8; i++ {
+			wg.Add(1)
+			go func() {
+				defer wg.Done()
+				res, err := ResolveTree(&tree, input)
+				assert.NoError(t, err)
+				assert.Equal(t, "high", res)
+			}()
+		}
+		wg.Wait()
+		scores := input.(map[string]interface{})["scores"]
+		assert.Equal(t, []interface{}{json.Number("4"), json.Number("7.5")}, scores)
+	})
+	t.Run("tree functions replace default ones", func(t *testing.T) {
+		custom := function.PreProcessFn{Name: "Lower", Function: func(str interface{}, args ...interface{}) (interface{}, error) {
+			return "custom@example.com", nil
+		}}
+		tree, err := NewTree("custom", nil, custom)
+		require.NoError(t, err)
+		require.NoError(t, json.Unmarshal(nested, tree))
+		res, err := ResolveTree(tree, map[string]interface{}{"email": "bob@gmail.com", "scores": []interface{}{20}})
+		require.NoError(t, err)
+		assert.Equal(t, "high", res)
+	})
+}
+
+func TestResolveTree_SetComparers(t *testing.T) {
+	data := []byte(`{"name": "regions", "nodes": [
+		{"id": 0, "parentId": -1, "preProcessFnName": "GetMapValue", "preProcessFnArgs": [{"Value": "country", "Type": "string"}]},
+		{"id": 1, "parentId": 0, "comparer": {"type": "in"}, "valueToCompare": {"Value": ["UY", "AR", "BR", "PY"], "Type": "list"}, "result": {"Value": "mercosur", "Type": "string"}},
+		{"id": 2, "parentId": 0, "comparer": {"type": "notIn"}, "valueToCompare": {"Value": ["US", "CA"], "Type": "list"}, "result": {"Value": "other", "Type": "string"}},
+		{"id": 3, "parentId": 0, "default": true, "result": {"Value": "north america", "Type": "string"}}
+	]}`)
+	var tree Tree
+	require.NoError(t, json.Unmarshal(data, &tree))
+	evaluator, err := Compile(&tree)
+	require.NoError(t, err)
+	tests := map[string]interface{}{"UY": "mercosur", "PY": "mercosur", "CL": "other", "US": "north america"}
+	for country, expected := range tests {
+		t.Run(country, func(t *testing.T) {
+			input := map[string]interface{}{"country": country}
+			res, err := ResolveTree(&tree, input)
+			require.NoError(t, err)
+			assert.Equal(t, expected, res)
+			res, err = evaluator.Resolve(input)
+			require.NoError(t, err)
+			assert.Equal(t, expected, res)
+		})
+	}
+	t.Run("marshal", func(t *testing.T) {
+		b, err := json.Marshal(&tree)
+		require.NoError(t, err)
+		assert.Contains(t, string(b), `"valueToCompare":{"Value":["UY","AR","BR","PY"],"Type":"list"}`)
+	})
+	t.Run("label", func(t *testing.T) {
+		assert.Equal(t, `in ["UY", "AR", "BR", "PY"]`, edgeLabel(tree.Root.Children[0]))
+		assert.Equal(t, `not in ["US", "CA"]`, edgeLabel(tree.Root.Children[1]))
+	})
+}
+
+func TestResolveTree_Between(t *testing.T) {
+	data := []byte(`{"name": "ageBuckets", "root": {"children": [
+		{"comparer": {"type": "between", "upperInclusive": true}, "valueToCompare": {"Value": [30, 60], "Type": "list"}, "result": {"Value": "adult", "Type": "string"}},
+		{"comparer": {"type": "between", "lowerInclusive": true, "upperInclusive": true}, "valueToCompare": {"Value": [0, 30], "Type": "list"}, "result": {"Value": "young", "Type": "string"}},
+		{"default": true, "result": {"Value": "senior", "Type": "string"}}
+	]}}`)
+	var tree Tree
+	require.NoError(t, json.Unmarshal(data, &tree))
+	evaluator, err := Compile(&tree)
+	require.NoError(t, err)
+	tests := map[int]interface{}{0: "young", 30: "young", 31: "adult", 60: "adult", 61: "senior"}
+	for age, expected := range tests {
+		res, err := ResolveTree(&tree, age)
+		require.NoError(t, err)
+		assert.Equal(t, expected, res, age)
+		res, err = evaluator.Resolve(age)
+		require.NoError(t, err)
+		assert.Equal(t, expected, res, age)
+	}
+	assert.Equal(t, "in (30, 60]", edgeLabel(tree.Root.Children[0]))
+	assert.Equal(t, "in [0, 30]", edgeLabel(tree.Root.Children[1]))
+}
+
+func TestResolveTree_NumericCoercion(t *testing.T) {
+	data := []byte(`{"name": "limits", "root": {"children": [
+		{"comparer": {"type": "gt"}, "valueToCompare": {"Value": 1000, "Type": "int64"}, "result": {"Value": "high", "Type": "string"}},
+		{"comparer": {"type": "in"}, "valueToCompare": {"Value": [0, 1, 2], "Type": "list"}, "result": {"Value": "tiny", "Type": "string"}},
+		{"comparer": {"type": "between", "lowerInclusive": true, "upperInclusive": true}, "valueToCompare": {"Value": [3, 1000], "Type": "list"}, "result": {"Value": "normal", "Type": "string"}}
+	]}}`)
+	var tree Tree
+	require.NoError(t, json.Unmarshal(data, &tree))
+	inputs := map[string]struct {
+		input    interface{}
+		expected interface{}
+	}{
+		"int":         {input: 2000, expected: "high"},
+		"uint8":       {input: uint8(1), expected: "tiny"},
+		"float":       {input: 2.0, expected: "tiny"},
+		"int32":       {input: int32(500), expected: "normal"},
+		"float bound": {input: 1000.0, expected: "normal"},
+		"float above": {input: 1000.5, expected: "high"},
+	}
+	t.Run("disabled", func(t *testing.T) {
+		_, err := ResolveTree(&tree, 2000)
+		assert.True(t, errors.Is(err, ErrNoMatchingChild))
+	})
+	tree.NumericCoercion = true
+	evaluator, err := Compile(&tree)
+	require.NoError(t, err)
+	for name, tc := range inputs {
+		t.Run(name, func(t *testing.T) {
+			res, err := ResolveTree(&tree, tc.input)
+			require.NoError(t, err)
+			assert.Equal(t, tc.expected, res)
+			res, err = evaluator.Resolve(tc.input)
+			require.NoError(t, err)
+			assert.Equal(t, tc.expected, res)
+		})
+	}
+	t.Run("nan", func(t *testing.T) {
+		_, err := ResolveTree(&tree, math.NaN())
+		assert.True(t, errors.Is(err, ErrNoMatchingChild))
+		_, err = evaluator.Resolve(math.NaN())
+		assert.True(t, errors.Is(err, ErrNoMatchingChild))
+	})
+	t.Run("json", func(t *testing.T) {
+		b, err := json.Marshal(&tree)
+		require.NoError(t, err)
+		assert.Contains(t, string(b), `"numericCoercion":true`)
+		var decoded Tree
+		require.NoError(t, json.Unmarshal(b, &decoded))
+		assert.True(t, decoded.NumericCoercion)
+	})
+}
+
+func TestResolveTree_CompositeComparers(t *testing.T) {
+	data := []byte(`{"name": "access", "root": {
+		"preProcessFnName": "GetMapValue", "preProcessFnArgs": [{"Value": "age", "Type": "string"}],
+		"children": [
+			{"comparer": {"type": "and", "operands": [
+				{"comparer": {"type": "gt", "equal": true}, "value": {"Value": 18, "Type": "int"}},
+				{"comparer": {"type": "lt"}, "value": {"Value": 65, "Type": "int"}}
+			]},
+			 "preProcessFnName": "GetMapValue", "preProcessFnArgs": [{"Value": "status", "Type": "string"}],
+			 "children": [
+				{"comparer": {"type": "not", "operand": {"comparer": {"type": "eq"}, "value": {"Value": "banned", "Type": "string"}}},
+				 "result": {"Value": "allowed", "Type": "string"}},
+				{"default": true, "result": {"Value": "banned", "Type": "string"}}
+			 ]},
+			{"default": true, "result": {"Value": "out of range", "Type": "string"}}
+		]}}`)
+	var tree Tree
+	require.NoError(t, json.Unmarshal(data, &tree))
+	evaluator, err := Compile(&tree)
+	require.NoError(t, err)
+	tests := map[string]struct {
+		input    map[string]interface{}
+		expected interface{}
+	}{
+		"allowed":     {input: map[string]interface{}{"age": 30, "status": "active"}, expected: "allowed"},
+		"banned":      {input: map[string]interface{}{"age": 30, "status": "banned"}, expected: "banned"},
+		"young":       {input: map[string]interface{}{"age": 17}, expected: "out of range"},
+		"old":         {input: map[string]interface{}{"age": 65}, expected: "out of range"},
+		"lower bound": {input: map[string]interface{}{"age": 18, "status": "new"}, expected: "allowed"},
+		"float age":   {input: map[string]interface{}{"age": 30.0}, expected: "out of range"},
+	}
+	for name, tc := range tests {
+		t.Run(name, func(t *testing.T) {
+			res, err := ResolveTree(&tree, tc.input)
+			require.NoError(t, err)
+			assert.Equal(t, tc.expected, res)
+			res, err = evaluator.Resolve(tc.input)
+			require.NoError(t, err)
+			assert.Equal(t, tc.expected, res)
+		})
+	}
+	t.Run("trace", func(t *testing.T) {
+		_, trace, err := ExplainTree(&tree, map[string]interface{}{"age": 30, "status": "active"})
+		require.NoError(t, err)
+		assert.Nil(t, trace.Steps[0].Comparisons[0].ValueToCompare)
+		assert.True(t, trace.Steps[0].Comparisons[0].Matched)
+	})
+	t.Run("round trip", func(t *testing.T) {
+		b, err := json.Marshal(&tree)
+		require.NoError(t, err)
+		var decoded Tree
+		require.NoError(t, json.Unmarshal(b, &decoded))
+		assert.Equal(t, tree.Root.Children[0].Comparer, decoded.Root.Children[0].Comparer)
+	})
+	t.Run("labels", func(t *testing.T) {
+		assert.Equal(t, ">= 18 and < 65", strings.Trim(edgeLabel(tree.Root.Children[0]), "()"))
+		assert.Equal(t, `not (== "banned")`, edgeLabel(tree.Root.Children[0].Children[0]))
+	})
+}
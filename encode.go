@@ -3,8 +3,40 @@ package ddt
 import (
 	"encoding/json"
 	"errors"
+	"fmt"
+	"sort"
 )
 
+// Errors reported when the flat nodes representation does not describe a tree
+var (
+	ErrMissingRoot     = errors.New("missing root node")
+	ErrMultipleRoots   = errors.New("multiple root nodes")
+	ErrDuplicateNodeID = errors.New("duplicate node ids")
+	ErrSelfParent      = errors.New("nodes are their own parent")
+	ErrNodeCycle       = errors.New("nodes form a cycle")
+	ErrOrphanNodes     = errors.New("orphan nodes not reachable from root")
+)
+
+// StructureError is returned when decoding a tree whose nodes do not form a
+// tree, Err is one of the structure errors and IDs the offending node ids.
+type StructureError struct {
+	Err error
+	IDs []int
+}
+
+// Error implements the error interface
+func (e *StructureError) Error() string {
+	if len(e.IDs) == 0 {
+		return e.Err.Error()
+	}
+	return fmt.Sprintf("%s: %v", e.Err, e.IDs)
+}
+
+// Unwrap returns the structure error kind, to be used with errors.Is
+func (e *StructureError) Unwrap() error {
+	return e.Err
+}
+
 // MarshalJSON ...
 func (t *Tree) MarshalJSON() ([]byte, error) {
 	type TreeAlias Tree
@@ -18,22 +50,54 @@ func (t *Tree) MarshalJSON() ([]byte, error) {
 	})
 }
 
-// UnmarshalJSON ...
+// UnmarshalJSON decodes the flat nodes representation or the nested one
+// (detected by the "root" key), the decoded tree is validated and t is only
+// modified when it is valid.
 func (t *Tree) UnmarshalJSON(data []byte) error {
 	type TreeAlias Tree
+	decoded := &Tree{Functions: t.Functions, Comparers: t.Comparers}
+	if decoded.Functions == nil {
+		decoded.Functions = addNewPreProcessFn(nil)
+	}
 	auxTree := &struct {
-		Nodes []*Node `json:"nodes"`
+		Nodes []json.RawMessage `json:"nodes"`
+		Root  json.RawMessage   `json:"root"`
 		*TreeAlias
 	}{
-		Nodes:     []*Node{},
-		TreeAlias: (*TreeAlias)(t),
+		TreeAlias: (*TreeAlias)(decoded),
 	}
 	err := json.Unmarshal(data, auxTree)
 	if err != nil {
 		return err
 	}
+	if auxTree.Root != nil {
+		err = decodeNestedNodes(decoded, auxTree.Root)
+	} else {
+		err = decodeFlatNodes(decoded, auxTree.Nodes)
+	}
+	if err != nil {
+		return err
+	}
+	if err := decoded.Validate(); err != nil {
+		return err
+	}
+	*t = *decoded
+	return nil
+}
+
+func decodeFlatNodes(t *Tree, data []json.RawMessage) error {
+	nodes := make([]*Node, len(data))
+	for i, d := range data {
+		nodes[i] = &Node{}
+		if err := nodes[i].decode(d, t.Comparers); err != nil {
+			return err
+		}
+	}
+	if err := checkStructure(nodes); err != nil {
+		return err
+	}
 	keyParentOf := map[int][]*Node{}
-	for _, n := range auxTree.Nodes {
+	for _, n := range nodes {
 		if err := addPreprocessFn(t, n); err != nil {
 			return err
 		}
@@ -48,8 +112,98 @@ func (t *Tree) UnmarshalJSON(data []byte) error {
 	return nil
 }
 
+// checkStructure verifies the id/parentId relations of the flat nodes
+// describe a single tree, reporting the first kind of problem found.
+func checkStructure(nodes []*Node) error {
+	byID := map[int]*Node{}
+	var duplicated, roots, selfParent []int
+	for _, n := range nodes {
+		if _, ok := byID[n.ID]; ok {
+			duplicated = append(duplicated, n.ID)
+		}
+		byID[n.ID] = n
+		if n.ParentID == -1 {
+			roots = append(roots, n.ID)
+		}
+		if n.ParentID == n.ID {
+			selfParent = append(selfParent, n.ID)
+		}
+	}
+	if len(duplicated) != 0 {
+		return newStructureError(ErrDuplicateNodeID, duplicated)
+	}
+	if _, ok := byID[0]; !ok {
+		return newStructureError(ErrMissingRoot, nil)
+	}
+	if len(roots) > 1 {
+		return newStructureError(ErrMultipleRoots, roots)
+	}
+	if len(selfParent) != 0 {
+		return newStructureError(ErrSelfParent, selfParent)
+	}
+	cycles, orphans := unreachableNodes(nodes, byID)
+	if len(cycles) != 0 {
+		return newStructureError(ErrNodeCycle, cycles)
+	}
+	if len(orphans) != 0 {
+		return newStructureError(ErrOrphanNodes, orphans)
+	}
+	return nil
+}
+
+const (
+	reachesRoot = iota + 1
+	orphanNode
+	cycleNode
+)
+
+// unreachableNodes follows the parent ids of every node, returning the ids
+// of the nodes that are part of a cycle and the ones that do not reach the
+// root for any other reason.
+func unreachableNodes(nodes []*Node, byID map[int]*Node) (cycles, orphans []int) {
+	state := map[int]int{0: reachesRoot}
+	for _, n := range nodes {
+		var path []int
+		onPath := map[int]int{}
+		pathState := orphanNode
+		for current, ok := n, true; ok; current, ok = byID[current.ParentID] {
+			if s, known := state[current.ID]; known {
+				if s == reachesRoot {
+					pathState = reachesRoot
+				}
+				break
+			}
+			if idx, seen := onPath[current.ID]; seen {
+				for _, id := range path[idx:] {
+					state[id] = cycleNode
+					cycles = append(cycles, id)
+				}
+				path = path[:idx]
+				break
+			}
+			onPath[current.ID] = len(path)
+			path = append(path, current.ID)
+		}
+		for _, id := range path {
+			state[id] = pathState
+			if pathState == orphanNode {
+				orphans = append(orphans, id)
+			}
+		}
+	}
+	return cycles, orphans
+}
+
+func newStructureError(err error, ids []int) *StructureError {
+	sort.Ints(ids)
+	return &StructureError{Err: err, IDs: ids}
+}
+
 func getAllNodes(root *Node) []*Node {
-	var res []*Node
+	res := []*Node{}
+	if root == nil {
+		return res
+	}
 	queue := []*Node{root}
 	for len(queue) != 0 {
 		top := queue[0]
@@ -74,13 +228,27 @@ func setChildrenToParentNodes(root *Node, keyParentOf map[int][]*Node) {
 	}
 }
 
+// addPreprocessFn sets the implementation of the pre process functions of the
+// node by name, a missing function is a NodeError
 func addPreprocessFn(t *Tree, n *Node) error {
 	if !n.PreProcessFn.Empty() {
 		preProcessFn, ok := t.Functions[n.PreProcessFn.Name]
 		if !ok {
-			return errors.New("function name not found")
+			return &NodeError{NodeID: n.ID, Msg: fmt.Sprintf("pre process function %q not found", n.PreProcessFn.Name)}
 		}
 		n.PreProcessFn.Function = preProcessFn.Function
+		n.PreProcessFn.ContextFunction = preProcessFn.ContextFunction
+	}
+	for i, s := range n.Pipeline {
+		if s == nil {
+			continue
+		}
+		preProcessFn, ok := t.Functions[s.PreProcessFn.Name]
+		if !ok {
+			return &NodeError{NodeID: n.ID, Msg: fmt.Sprintf("pipeline step %d: pre process function %q not found", i, s.PreProcessFn.Name)}
+		}
+		s.PreProcessFn.Function = preProcessFn.Function
+		s.PreProcessFn.ContextFunction = preProcessFn.ContextFunction
 	}
 	return nil
 }
@@ -0,0 +1,94 @@
+package ddt
+
+import (
+	"encoding/json"
+	"errors"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"github.com/sgrodriguez/ddt/compare"
+)
+
+func TestUnmarshalJSON_StructureErrors(t *testing.T) {
+	leaf := func(id, parentID string) string {
+		return `{"id":` + id + `,"parentId":` + parentID + `,"comparer":{"type":"eq"},"valueToCompare":{"Value":1,"Type":"int"},"result":{"Value":"leaf","Type":"string"}}`
+	}
+	root := `{"id":0,"parentId":-1}`
+	testCases := map[string]struct {
+		nodes       string
+		expectedErr error
+		expectedIDs []int
+	}{
+		"missing root": {
+			nodes:       leaf("1", "0") + "," + leaf("2", "1"),
+			expectedErr: ErrMissingRoot,
+		},
+		"multiple roots": {
+			nodes:       root + "," + leaf("1", "-1") + "," + leaf("2", "0"),
+			expectedErr: ErrMultipleRoots,
+			expectedIDs: []int{0, 1},
+		},
+		"duplicate ids": {
+			nodes:       root + "," + leaf("1", "0") + "," + leaf("1", "0"),
+			expectedErr: ErrDuplicateNodeID,
+			expectedIDs: []int{1},
+		},
+		"self parent": {
+			nodes:       root + "," + leaf("1", "0") + "," + leaf("2", "2"),
+			expectedErr: ErrSelfParent,
+			expectedIDs: []int{2},
+		},
+		"cycle": {
+			nodes:       root + "," + leaf("1", "0") + "," + leaf("2", "4") + "," + leaf("3", "2") + "," + leaf("4", "3") + "," + leaf("5", "4"),
+			expectedErr: ErrNodeCycle,
+			expectedIDs: []int{2, 3, 4},
+		},
+		"orphans": {
+			nodes:       root + "," + leaf("1", "0") + "," + leaf("2", "7") + "," + leaf("3", "2"),
+			expectedErr: ErrOrphanNodes,
+			expectedIDs: []int{2, 3},
+		},
+	}
+	for name, tc := range testCases {
+		t.Run(name, func(t *testing.T) {
+			ut := userTree()
+			err := json.Unmarshal([]byte(`{"name":"invalid","nodes":[`+tc.nodes+`]}`), ut)
+			require.Error(t, err)
+			assert.True(t, errors.Is(err, tc.expectedErr))
+			var structureErr *StructureError
+			require.True(t, errors.As(err, &structureErr))
+			assert.Equal(t, tc.expectedIDs, structureErr.IDs)
+			// the previous tree is kept
+			assert.Equal(t, "userTree", ut.Name)
+			assert.Equal(t, 2, len(ut.Root.Children))
+		})
+	}
+}
+
+func TestUnmarshalJSON_ZeroValueTree(t *testing.T) {
+	var tree Tree
+	b, err := json.Marshal(userTree())
+	require.NoError(t, err)
+	require.NoError(t, json.Unmarshal(b, &tree))
+	result, err := ResolveTree(&tree, newUser(11, "SANTIAGO", "LUCIA"))
+	require.NoError(t, err)
+	assert.Equal(t, "node3", result)
+}
+
+func TestUnmarshalJSON_CompilesPatterns(t *testing.T) {
+	var n Node
+	require.NoError(t, json.Unmarshal([]byte(`{"id": 1, "parentId": 0,
+		"comparer": {"type": "not", "operand": {"comparer": {"type": "regex"}, "value": {"Value": "^a+$", "Type": "string"}}},
+		"result": {"Value": 1, "Type": "int"}}`), &n))
+	not, ok := n.Comparer.(*compare.Not)
+	require.True(t, ok)
+	r, ok := not.Operand.Comparer.(*compare.Regex)
+	require.True(t, ok)
+	re, err := r.Regexp("^a+$")
+	require.NoError(t, err)
+	again, err := r.Regexp("^a+$")
+	require.NoError(t, err)
+	assert.Same(t, re, again, "the pattern is compiled decoding the node")
+}
@@ -0,0 +1,81 @@
+package ddt
+
+import (
+	"errors"
+	"fmt"
+)
+
+// Errors returned while resolving a tree, the errors returned by ResolveTree
+// can be checked against them with errors.Is. ErrNoMatchingChild means the
+// input did not match any rule, while ErrInvalidLeaf means the tree is
+// broken. ErrPreProcess wraps the error returned by the pre process function.
+var (
+	ErrNoMatchingChild = errors.New("value not found when comparing with all children nodes")
+	ErrPreProcess      = errors.New("pre process function failed")
+	ErrInvalidLeaf     = errors.New("leaf node without result")
+)
+
+// NoMatchingChildError is returned when no child of a node matches the value
+// to compare and the node has no default child
+type NoMatchingChildError struct {
+	NodeID int
+	Depth  int
+	// Value is the pre processed value compared against the children
+	Value interface{}
+}
+
+// Error implements the error interface
+func (e *NoMatchingChildError) Error() string {
+	return fmt.Sprintf("node %d (depth %d): %s", e.NodeID, e.Depth, ErrNoMatchingChild)
+}
+
+// Is reports whether target is ErrNoMatchingChild
+func (e *NoMatchingChildError) Is(target error) bool {
+	return target == ErrNoMatchingChild
+}
+
+// PreProcessError is returned when the pre process function of a node fails
+type PreProcessError struct {
+	NodeID       int
+	Depth        int
+	PreProcessFn string
+	// Expression is the expression of the node when it failed instead
+	Expression string
+	// Value is the input given to the pre process function
+	Value interface{}
+	Err   error
+}
+
+// Error implements the error interface
+func (e *PreProcessError) Error() string {
+	if e.Expression != "" {
+		return fmt.Sprintf("node %d (depth %d): expression %q failed: %v", e.NodeID, e.Depth, e.Expression, e.Err)
+	}
+	return fmt.Sprintf("node %d (depth %d): pre process function %q failed: %v", e.NodeID, e.Depth, e.PreProcessFn, e.Err)
+}
+
+// Is reports whether target is ErrPreProcess
+func (e *PreProcessError) Is(target error) bool {
+	return target == ErrPreProcess
+}
+
+// Unwrap returns the error returned by the pre process function
+func (e *PreProcessError) Unwrap() error {
+	return e.Err
+}
+
+// InvalidLeafError is returned when the resolution reaches a leaf without result
+type InvalidLeafError struct {
+	NodeID int
+	Depth  int
+}
+
+// Error implements the error interface
+func (e *InvalidLeafError) Error() string {
+	return fmt.Sprintf("node %d (depth %d): %s", e.NodeID, e.Depth, ErrInvalidLeaf)
+}
+
+// Is reports whether target is ErrInvalidLeaf
+func (e *InvalidLeafError) Is(target error) bool {
+	return target == ErrInvalidLeaf
+}
@@ -0,0 +1,67 @@
+package expr
+
+import (
+	"context"
+
+	"github.com/sgrodriguez/ddt/function"
+)
+
+// builtin is a function callable from expressions, the first argument is the
+// input of the function and the rest its args
+type builtin func(str interface{}, args ...interface{}) (interface{}, error)
+
+// builtins are the functions of the standard library of pre process functions
+// available in expressions, they have no side effects
+var builtins = map[string]builtin{
+	// strings
+	"lower":     function.Lower,
+	"upper":     function.Upper,
+	"trim":      function.Trim,
+	"substring": function.Substring,
+	"split":     function.Split,
+	// numbers
+	"abs":   function.Abs,
+	"round": function.Round,
+	"floor": function.Floor,
+	"ceil":  function.Ceil,
+	// collections
+	"len":      function.Len,
+	"contains": function.Contains,
+	"sum":      function.Sum,
+	"min":      function.Min,
+	"max":      function.Max,
+	// casts
+	"int":     function.ToInt,
+	"int64":   function.ToInt64,
+	"float64": function.ToFloat64,
+	"string":  function.ToString,
+	"bool":    function.ToBool,
+}
+
+// callNode is a call to a built in function (ie len(input.Orders))
+type callNode struct {
+	name string
+	fn   builtin
+	args []node
+	pos  int
+}
+
+func (n *callNode) eval(ctx context.Context, input interface{}) (interface{}, error) {
+	args := make([]interface{}, len(n.args))
+	for i, a := range n.args {
+		v, err := a.eval(ctx, input)
+		if err != nil {
+			return nil, err
+		}
+		args[i] = v
+	}
+	// the built in functions are the expensive part of an evaluation
+	if err := ctx.Err(); err != nil {
+		return nil, err
+	}
+	res, err := n.fn(args[0], args[1:]...)
+	if err != nil {
+		return nil, &EvalError{Column: n.pos + 1, Msg: n.name, Err: err}
+	}
+	return res, nil
+}
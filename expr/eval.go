@@ -0,0 +1,411 @@
+package expr
+
+import (
+	"context"
+	"math"
+	"reflect"
+	"strings"
+
+	"github.com/sgrodriguez/ddt/compare"
+	"github.com/sgrodriguez/ddt/function"
+)
+
+// node is a node of the syntax tree of an expression
+type node interface {
+	eval(ctx context.Context, input interface{}) (interface{}, error)
+}
+
+type literalNode struct {
+	v interface{}
+}
+
+func (n *literalNode) eval(context.Context, interface{}) (interface{}, error) {
+	return n.v, nil
+}
+
+type inputNode struct{}
+
+func (n *inputNode) eval(_ context.Context, input interface{}) (interface{}, error) {
+	return input, nil
+}
+
+type listNode struct {
+	elems []node
+}
+
+func (n *listNode) eval(ctx context.Context, input interface{}) (interface{}, error) {
+	list := make([]interface{}, len(n.elems))
+	for i, e := range n.elems {
+		v, err := e.eval(ctx, input)
+		if err != nil {
+			return nil, err
+		}
+		list[i] = v
+	}
+	return list, nil
+}
+
+// fieldNode is an exported struct field or a string map key (ie input.Age)
+type fieldNode struct {
+	x    node
+	name string
+	pos  int
+}
+
+func (n *fieldNode) eval(ctx context.Context, input interface{}) (interface{}, error) {
+	x, err := n.x.eval(ctx, input)
+	if err != nil {
+		return nil, err
+	}
+	v, ok := indirect(reflect.ValueOf(x))
+	if !ok {
+		return nil, evalErrorf(n.pos, "field %s of nil value", n.name)
+	}
+	switch v.Kind() {
+	case reflect.Struct:
+		f, ok := v.Type().FieldByName(n.name)
+		if !ok || f.PkgPath != "" {
+			return nil, evalErrorf(n.pos, "%s has no field %s", v.Type(), n.name)
+		}
+		for i, index := range f.Index {
+			if i != 0 && v.Kind() == reflect.Ptr {
+				if v.IsNil() {
+					return nil, evalErrorf(n.pos, "field %s of nil value", n.name)
+				}
+				v = v.Elem()
+			}
+			v = v.Field(index)
+		}
+		if !v.CanInterface() {
+			return nil, evalErrorf(n.pos, "%s has no field %s", v.Type(), n.name)
+		}
+		return v.Interface(), nil
+	case reflect.Map:
+		if v.Type().Key().Kind() != reflect.String {
+			break
+		}
+		elem := v.MapIndex(reflect.ValueOf(n.name).Convert(v.Type().Key()))
+		if !elem.IsValid() {
+			return nil, evalErrorf(n.pos, "key %q not found", n.name)
+		}
+		return elem.Interface(), nil
+	}
+	return nil, evalErrorf(n.pos, "field %s of %s", n.name, v.Type())
+}
+
+// indexNode is an element of a slice, array or string or a map value (ie
+// input.Orders[0])
+type indexNode struct {
+	x, index node
+	pos      int
+}
+
+func (n *indexNode) eval(ctx context.Context, input interface{}) (interface{}, error) {
+	x, err := n.x.eval(ctx, input)
+	if err != nil {
+		return nil, err
+	}
+	index, err := n.index.eval(ctx, input)
+	if err != nil {
+		return nil, err
+	}
+	v, ok := indirect(reflect.ValueOf(x))
+	if !ok {
+		return nil, evalErrorf(n.pos, "index of nil value")
+	}
+	switch v.Kind() {
+	case reflect.Slice, reflect.Array, reflect.String:
+		num, _ := compare.NumberOf(index)
+		i, ok := num.Int64()
+		if !ok {
+			return nil, evalErrorf(n.pos, "invalid index %v", index)
+		}
+		if v.Kind() == reflect.String {
+			runes := []rune(v.String())
+			if i < 0 || i >= int64(len(runes)) {
+				return nil, evalErrorf(n.pos, "index %d out of range", i)
+			}
+			return string(runes[i]), nil
+		}
+		if i < 0 || i >= int64(v.Len()) {
+			return nil, evalErrorf(n.pos, "index %d out of range", i)
+		}
+		return v.Index(int(i)).Interface(), nil
+	case reflect.Map:
+		key, ok := mapKey(index, v.Type().Key())
+		if !ok {
+			return nil, evalErrorf(n.pos, "invalid key %v for %s", index, v.Type())
+		}
+		elem := v.MapIndex(key)
+		if !elem.IsValid() {
+			return nil, evalErrorf(n.pos, "key %v not found", index)
+		}
+		return elem.Interface(), nil
+	}
+	return nil, evalErrorf(n.pos, "index of %s", v.Type())
+}
+
+// indirect dereferences pointers and interfaces, it is not ok for nil values
+func indirect(v reflect.Value) (reflect.Value, bool) {
+	for v.Kind() == reflect.Ptr || v.Kind() == reflect.Interface {
+		if v.IsNil() {
+			return v, false
+		}
+		v = v.Elem()
+	}
+	return v, v.IsValid()
+}
+
+// mapKey converts k to the key type t, numbers are converted between integer
+// kinds when they are integral
+func mapKey(k interface{}, t reflect.Type) (reflect.Value, bool) {
+	v := reflect.ValueOf(k)
+	if !v.IsValid() {
+		return v, false
+	}
+	if v.Type().AssignableTo(t) {
+		return v, true
+	}
+	if v.Kind() == reflect.String && t.Kind() == reflect.String {
+		return v.Convert(t), true
+	}
+	n, _ := compare.NumberOf(k)
+	i, ok := n.Int64()
+	if !ok {
+		return v, false
+	}
+	switch t.Kind() {
+	case reflect.Int, reflect.Int8, reflect.Int16, reflect.Int32, reflect.Int64:
+		key := reflect.New(t).Elem()
+		key.SetInt(i)
+		return key, key.Int() == i
+	case reflect.Uint, reflect.Uint8, reflect.Uint16, reflect.Uint32, reflect.Uint64, reflect.Uintptr:
+		key := reflect.New(t).Elem()
+		key.SetUint(uint64(i))
+		return key, i >= 0 && key.Uint() == uint64(i)
+	}
+	return v, false
+}
+
+type unaryNode struct {
+	op  string
+	x   node
+	pos int
+}
+
+func (n *unaryNode) eval(ctx context.Context, input interface{}) (interface{}, error) {
+	x, err := n.x.eval(ctx, input)
+	if err != nil {
+		return nil, err
+	}
+	if n.op == "!" {
+		b, ok := x.(bool)
+		if !ok {
+			return nil, evalErrorf(n.pos, "invalid operation !%v (%T)", x, x)
+		}
+		return !b, nil
+	}
+	num, ok := compare.NumberOf(x)
+	if !ok {
+		return nil, evalErrorf(n.pos, "invalid operation -%v (%T)", x, x)
+	}
+	if num.IsFloat() {
+		return -num.Float64(), nil
+	}
+	i, ok := num.Int64()
+	if !ok || i == math.MinInt64 {
+		return nil, evalErrorf(n.pos, "integer overflow")
+	}
+	res, ok := integer(-i, isInt(x))
+	if !ok {
+		return nil, evalErrorf(n.pos, "integer overflow")
+	}
+	return res, nil
+}
+
+type binaryNode struct {
+	op          string
+	left, right node
+	pos         int
+}
+
+func (n *binaryNode) eval(ctx context.Context, input interface{}) (interface{}, error) {
+	left, err := n.left.eval(ctx, input)
+	if err != nil {
+		return nil, err
+	}
+	if n.op == "&&" || n.op == "||" {
+		return n.logical(ctx, input, left)
+	}
+	right, err := n.right.eval(ctx, input)
+	if err != nil {
+		return nil, err
+	}
+	switch n.op {
+	case "==":
+		return equal(left, right), nil
+	case "!=":
+		return !equal(left, right), nil
+	case "<", "<=", ">", ">=":
+		return n.compare(left, right)
+	case "in":
+		res, err := function.Contains(right, left)
+		if err != nil {
+			return nil, evalErrorf(n.pos, "invalid operation %v in %v (%T)", left, right, right)
+		}
+		return res, nil
+	}
+	return n.arithmetic(left, right)
+}
+
+// logical evaluates && and || with short circuit
+func (n *binaryNode) logical(ctx context.Context, input, left interface{}) (interface{}, error) {
+	l, ok := left.(bool)
+	if !ok {
+		return nil, evalErrorf(n.pos, "invalid operation %v %s (%T), operands must be bool", left, n.op, left)
+	}
+	if (n.op == "&&" && !l) || (n.op == "||" && l) {
+		return l, nil
+	}
+	right, err := n.right.eval(ctx, input)
+	if err != nil {
+		return nil, err
+	}
+	r, ok := right.(bool)
+	if !ok {
+		return nil, evalErrorf(n.pos, "invalid operation %s %v (%T), operands must be bool", n.op, right, right)
+	}
+	return r, nil
+}
+
+// compare orders numbers by value and strings lexicographically
+func (n *binaryNode) compare(left, right interface{}) (interface{}, error) {
+	var c int
+	if compare.IsNumber(left) && compare.IsNumber(right) {
+		var ok bool
+		if c, ok = compare.CompareNumbers(left, right); !ok {
+			return false, nil
+		}
+	} else {
+		l, lok := left.(string)
+		r, rok := right.(string)
+		if !lok || !rok {
+			return nil, evalErrorf(n.pos, "invalid operation %v (%T) %s %v (%T)", left, left, n.op, right, right)
+		}
+		c = strings.Compare(l, r)
+	}
+	switch n.op {
+	case "<":
+		return c < 0, nil
+	case "<=":
+		return c <= 0, nil
+	case ">":
+		return c > 0, nil
+	}
+	return c >= 0, nil
+}
+
+// arithmetic evaluates + - * / and %, integers keep the int type when both
+// operands are ints and are int64 otherwise, failing on overflow. Any float
+// operand makes the result a float64. + also concatenates strings.
+func (n *binaryNode) arithmetic(left, right interface{}) (interface{}, error) {
+	l, lok := compare.NumberOf(left)
+	r, rok := compare.NumberOf(right)
+	if !lok || !rok {
+		ls, lok := left.(string)
+		rs, rok := right.(string)
+		if n.op == "+" && lok && rok {
+			return ls + rs, nil
+		}
+		return nil, evalErrorf(n.pos, "invalid operation %v (%T) %s %v (%T)", left, left, n.op, right, right)
+	}
+	if l.IsFloat() || r.IsFloat() {
+		x, y := l.Float64(), r.Float64()
+		switch n.op {
+		case "+":
+			return x + y, nil
+		case "-":
+			return x - y, nil
+		case "*":
+			return x * y, nil
+		case "/":
+			if y == 0 {
+				return nil, evalErrorf(n.pos, "division by zero")
+			}
+			return x / y, nil
+		}
+		return nil, evalErrorf(n.pos, "invalid operation %v %% %v, operands must be integers", left, right)
+	}
+	x, xok := l.Int64()
+	y, yok := r.Int64()
+	if !xok || !yok {
+		return nil, evalErrorf(n.pos, "integer overflow")
+	}
+	var res int64
+	overflow := false
+	switch n.op {
+	case "+":
+		res = x + y
+		overflow = (x > 0 && y > 0 && res < 0) || (x < 0 && y < 0 && res >= 0)
+	case "-":
+		res = x - y
+		overflow = (x >= 0 && y < 0 && res < 0) || (x < 0 && y > 0 && res >= 0)
+	case "*":
+		res = x * y
+		overflow = x != 0 && (res/x != y || (x == -1 && y == math.MinInt64))
+	case "/", "%":
+		if y == 0 {
+			return nil, evalErrorf(n.pos, "division by zero")
+		}
+		if n.op == "%" {
+			res = x % y
+			break
+		}
+		res = x / y
+		overflow = x == math.MinInt64 && y == -1
+	}
+	v, ok := integer(res, isInt(left) && isInt(right))
+	if overflow || !ok {
+		return nil, evalErrorf(n.pos, "integer overflow")
+	}
+	return v, nil
+}
+
+// equal compares numbers by value, nil with nil values (ie nil pointers) and
+// any other value deeply
+func equal(a, b interface{}) bool {
+	if compare.IsNumber(a) && compare.IsNumber(b) {
+		c, ok := compare.CompareNumbers(a, b)
+		return ok && c == 0
+	}
+	if isNil(a) || isNil(b) {
+		return isNil(a) && isNil(b)
+	}
+	return reflect.DeepEqual(a, b)
+}
+
+func isNil(v interface{}) bool {
+	if v == nil {
+		return true
+	}
+	rv := reflect.ValueOf(v)
+	switch rv.Kind() {
+	case reflect.Ptr, reflect.Map, reflect.Slice, reflect.Interface, reflect.Func, reflect.Chan:
+		return rv.IsNil()
+	}
+	return false
+}
+
+// isInt reports whether v is of the int kind, which is kept by the arithmetic
+func isInt(v interface{}) bool {
+	return reflect.ValueOf(v).Kind() == reflect.Int
+}
+
+// integer returns i as an int when isInt and as an int64 otherwise, it is not
+// ok when i overflows int
+func integer(i int64, isInt bool) (interface{}, bool) {
+	if !isInt {
+		return i, true
+	}
+	return int(i), int64(int(i)) == i
+}
@@ -0,0 +1,186 @@
+package expr
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"math"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+type Address struct {
+	City string
+}
+
+type order struct {
+	Total float64
+	Items []string
+}
+
+type customer struct {
+	*Address
+	Age     int
+	Months  int64
+	Score   uint8
+	Orders  []*order
+	Tags    map[string]bool
+	Limits  map[int]float64
+	Manager *customer
+	secret  string
+}
+
+func newCustomer() *customer {
+	return &customer{
+		Address: &Address{City: "Montevideo"},
+		Age:     30,
+		Months:  4,
+		Score:   7,
+		Orders:  []*order{{Total: 10.5, Items: []string{"a", "b"}}, {Total: 20}},
+		Tags:    map[string]bool{"vip": true},
+		Limits:  map[int]float64{1: 100},
+		secret:  "s",
+	}
+}
+
+func TestEval(t *testing.T) {
+	tests := map[string]struct {
+		source   string
+		expected interface{}
+	}{
+		"int arithmetic":     {source: "input.Age * 12", expected: 360},
+		"int64 arithmetic":   {source: "input.Age * 12 + input.Months", expected: int64(364)},
+		"uint arithmetic":    {source: "input.Score + 1", expected: int64(8)},
+		"float arithmetic":   {source: "input.Orders[0].Total * 2", expected: 21.0},
+		"int division":       {source: "input.Age / 7", expected: 4},
+		"float division":     {source: "input.Age / 4.0", expected: 7.5},
+		"modulo":             {source: "input.Age % 7", expected: 2},
+		"negative":           {source: "-input.Months", expected: int64(-4)},
+		"concat":             {source: "input.City + '!'", expected: "Montevideo!"},
+		"promoted field":     {source: "input.City", expected: "Montevideo"},
+		"len":                {source: "len(input.Orders) > 1", expected: true},
+		"nested index":       {source: "input.Orders[0].Items[1]", expected: "b"},
+		"index expression":   {source: "input.Orders[len(input.Orders) - 1].Total", expected: 20.0},
+		"string index":       {source: "input.City[0]", expected: "M"},
+		"map field":          {source: "input.Tags.vip", expected: true},
+		"map index":          {source: "input.Tags['vip']", expected: true},
+		"int map key":        {source: "input.Limits[1]", expected: 100.0},
+		"numeric equality":   {source: "input.Months == 4 && input.Score == 7.0", expected: true},
+		"numeric ordering":   {source: "input.Score < input.Age", expected: true},
+		"string ordering":    {source: "input.City >= 'M'", expected: true},
+		"nil pointer":        {source: "input.Manager == nil", expected: true},
+		"not nil":            {source: "input.Orders != nil", expected: true},
+		"in list":            {source: "input.City in ['Montevideo', 'Salto']", expected: true},
+		"in map":             {source: "'vip' in input.Tags", expected: true},
+		"in slice":           {source: "'c' in input.Orders[0].Items", expected: false},
+		"in string":          {source: "'video' in input.City", expected: true},
+		"short circuit and":  {source: "input.Manager != nil && input.Manager.Age > 3", expected: false},
+		"short circuit or":   {source: "input.Manager == nil || input.Manager.Age > 3", expected: true},
+		"builtins":           {source: "lower(input.City) == 'montevideo'", expected: true},
+		"builtin args":       {source: "round(input.Orders[0].Total / 3, 1)", expected: 3.5},
+		"cast":               {source: "int64(input.Age)", expected: int64(30)},
+		"float equality":     {source: "0.1 + 0.2 == 0.3", expected: false},
+		"different types eq": {source: "'1' == 1", expected: false},
+		"deep equality":      {source: "input.Orders[0].Items == ['a', 'b']", expected: false},
+	}
+	input := newCustomer()
+	for name, tc := range tests {
+		t.Run(name, func(t *testing.T) {
+			p, err := Compile(tc.source)
+			require.NoError(t, err)
+			res, err := p.Eval(input)
+			require.NoError(t, err)
+			assert.Equal(t, tc.expected, res)
+		})
+	}
+	t.Run("map input", func(t *testing.T) {
+		p, err := Compile("input.user.age + 1")
+		require.NoError(t, err)
+		res, err := p.Eval(map[string]interface{}{"user": map[string]interface{}{"age": 20}})
+		require.NoError(t, err)
+		assert.Equal(t, 21, res)
+	})
+	t.Run("json numbers", func(t *testing.T) {
+		p, err := Compile("input.items[input.index] * 2")
+		require.NoError(t, err)
+		res, err := p.Eval(map[string]interface{}{"index": json.Number("1"), "items": []interface{}{1, json.Number("2.5")}})
+		require.NoError(t, err)
+		assert.Equal(t, 5.0, res)
+	})
+}
+
+func TestEval_Errors(t *testing.T) {
+	tests := map[string]struct {
+		source   string
+		expected string
+	}{
+		"unexported field":  {source: "input.secret", expected: "column 7: expr.customer has no field secret"},
+		"missing field":     {source: "input.Name", expected: "column 7: expr.customer has no field Name"},
+		"nil field":         {source: "input.Manager.Age", expected: "column 15: field Age of nil value"},
+		"missing key":       {source: "input.Tags.gold", expected: `column 12: key "gold" not found`},
+		"missing int key":   {source: "input.Limits[2]", expected: "column 13: key 2 not found"},
+		"invalid key":       {source: "input.Limits['a']", expected: "column 13: invalid key a for map[int]float64"},
+		"out of range":      {source: "input.Orders[2]", expected: "column 13: index 2 out of range"},
+		"negative index":    {source: "input.Orders[-1]", expected: "column 13: index -1 out of range"},
+		"float index":       {source: "input.Orders[0.5]", expected: "column 13: invalid index 0.5"},
+		"field of int":      {source: "input.Age.Value", expected: "column 11: field Value of int"},
+		"index of int":      {source: "input.Age[0]", expected: "column 10: index of int"},
+		"division by zero":  {source: "input.Age / (input.Age - 30)", expected: "column 11: division by zero"},
+		"float div by zero": {source: "1.5 / 0", expected: "column 5: division by zero"},
+		"float modulo":      {source: "1.5 % 1", expected: "column 5: invalid operation 1.5 % 1, operands must be integers"},
+		"overflow":          {source: "input.Months * 9223372036854775807", expected: "column 14: integer overflow"},
+		"add overflow":      {source: "9223372036854775807 + 1", expected: "column 21: integer overflow"},
+		"sub overflow":      {source: "-9223372036854775807 - 2", expected: "column 22: integer overflow"},
+		"negate":            {source: "-input.City", expected: "column 1: invalid operation -Montevideo (string)"},
+		"not":               {source: "!input.Age", expected: "column 1: invalid operation !30 (int)"},
+		"and operand":       {source: "input.Age && true", expected: "column 11: invalid operation 30 && (int), operands must be bool"},
+		"or operand":        {source: "false || input.Age", expected: "column 7: invalid operation || 30 (int), operands must be bool"},
+		"compare types":     {source: "input.Age < 'a'", expected: "column 11: invalid operation 30 (int) < a (string)"},
+		"arithmetic types":  {source: "input.City - 1", expected: "column 12: invalid operation Montevideo (string) - 1 (int)"},
+		"in":                {source: "1 in input.Age", expected: "column 3: invalid operation 1 in 30 (int)"},
+		"builtin":           {source: "len(input.Age)", expected: "column 1: len: len invalid input: 30 (int)"},
+	}
+	input := newCustomer()
+	for name, tc := range tests {
+		t.Run(name, func(t *testing.T) {
+			p, err := Compile(tc.source)
+			require.NoError(t, err)
+			_, err = p.Eval(input)
+			var evalErr *EvalError
+			require.True(t, errors.As(err, &evalErr), "%v", err)
+			assert.EqualError(t, err, tc.expected)
+		})
+	}
+	t.Run("unsigned overflow", func(t *testing.T) {
+		p, err := Compile("input.big + 1")
+		require.NoError(t, err)
+		_, err = p.Eval(map[string]interface{}{"big": uint64(math.MaxUint64)})
+		assert.EqualError(t, err, "column 11: integer overflow")
+	})
+	t.Run("unwrap", func(t *testing.T) {
+		p, err := Compile("substring(input.City, 'a')")
+		require.NoError(t, err)
+		_, err = p.Eval(input)
+		assert.Error(t, errors.Unwrap(err))
+	})
+	t.Run("canceled", func(t *testing.T) {
+		p, err := Compile("len(input.Orders) > 1")
+		require.NoError(t, err)
+		ctx, cancel := context.WithCancel(context.Background())
+		cancel()
+		_, err = p.EvalContext(ctx, input)
+		assert.True(t, errors.Is(err, context.Canceled))
+		res, err := p.EvalContext(context.Background(), input)
+		require.NoError(t, err)
+		assert.Equal(t, true, res)
+	})
+	t.Run("nan", func(t *testing.T) {
+		p, err := Compile("input < 1")
+		require.NoError(t, err)
+		res, err := p.Eval(math.NaN())
+		require.NoError(t, err)
+		assert.Equal(t, false, res)
+	})
+}
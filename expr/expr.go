@@ -0,0 +1,101 @@
+// Package expr is a small expression language evaluated over the input of a
+// tree, like `input.Age * 12 + input.Months` or `len(input.Orders) > 3`.
+//
+// Expressions are sandboxed: they can only read the exported fields and map
+// keys of the input, call the built in functions and use the operators
+//
+//	||  &&  ==  !=  <  <=  >  >=  in  +  -  *  /  %  !
+//
+// (`and`, `or` and `not` can be used instead of `&&`, `||` and `!`). There
+// are no assignments, loops or method calls, so evaluating an expression
+// always ends and has no side effects.
+package expr
+
+import (
+	"context"
+	"fmt"
+)
+
+// maxDepth is the maximum nesting of an expression
+const maxDepth = 64
+
+// Program is a compiled expression, it is safe for concurrent use
+type Program struct {
+	source string
+	root   node
+}
+
+// Compile parses the source of an expression
+func Compile(source string) (*Program, error) {
+	tokens, err := tokenize(source)
+	if err != nil {
+		return nil, err
+	}
+	p := &parser{tokens: tokens}
+	root, err := p.parse()
+	if err != nil {
+		return nil, err
+	}
+	return &Program{source: source, root: root}, nil
+}
+
+// Eval evaluates the expression, input is the value of the `input` identifier
+func (p *Program) Eval(input interface{}) (interface{}, error) {
+	return p.EvalContext(context.Background(), input)
+}
+
+// EvalContext evaluates the expression like Eval, the context is checked
+// before calling each built in function
+func (p *Program) EvalContext(ctx context.Context, input interface{}) (interface{}, error) {
+	if err := ctx.Err(); err != nil {
+		return nil, err
+	}
+	return p.root.eval(ctx, input)
+}
+
+// String returns the source of the expression
+func (p *Program) String() string {
+	return p.source
+}
+
+// SyntaxError is returned compiling an invalid expression
+type SyntaxError struct {
+	// Column is the 1 based position of the error in the source
+	Column int
+	Msg    string
+}
+
+// Error implements the error interface
+func (e *SyntaxError) Error() string {
+	return fmt.Sprintf("syntax error at column %d: %s", e.Column, e.Msg)
+}
+
+// EvalError is returned when the evaluation of an expression fails
+type EvalError struct {
+	// Column is the 1 based position of the failing operation in the source
+	Column int
+	Msg    string
+	// Err is the error returned by a built in function
+	Err error
+}
+
+// Error implements the error interface
+func (e *EvalError) Error() string {
+	if e.Err != nil {
+		return fmt.Sprintf("column %d: %s: %v", e.Column, e.Msg, e.Err)
+	}
+	return fmt.Sprintf("column %d: %s", e.Column, e.Msg)
+}
+
+// Unwrap returns the error returned by the built in function
+func (e *EvalError) Unwrap() error {
+	return e.Err
+}
+
+func syntaxErrorf(pos int, format string, args ...interface{}) error {
+	return &SyntaxError{Column: pos + 1, Msg: fmt.Sprintf(format, args...)}
+}
+
+func evalErrorf(pos int, format string, args ...interface{}) error {
+	return &EvalError{Column: pos + 1, Msg: fmt.Sprintf(format, args...)}
+}
@@ -0,0 +1,165 @@
+package expr
+
+import (
+	"strings"
+	"unicode"
+	"unicode/utf8"
+)
+
+type tokenKind int
+
+const (
+	tokenEOF tokenKind = iota
+	tokenNumber
+	tokenString
+	tokenIdent
+	tokenOp
+)
+
+// token is a lexical token, pos is its byte offset in the source
+type token struct {
+	kind tokenKind
+	text string
+	pos  int
+}
+
+func (t token) String() string {
+	if t.kind == tokenEOF {
+		return "end of expression"
+	}
+	return "`" + t.text + "`"
+}
+
+// keywords are the operators written as words, they are replaced by their
+// symbol
+var keywords = map[string]string{"and": "&&", "or": "||", "not": "!", "in": "in"}
+
+var twoCharOps = []string{"==", "!=", "<=", ">=", "&&", "||"}
+
+const singleCharOps = "+-*/%<>!()[],."
+
+func tokenize(src string) ([]token, error) {
+	var tokens []token
+	for i := 0; i < len(src); {
+		r, size := utf8.DecodeRuneInString(src[i:])
+		switch {
+		case unicode.IsSpace(r):
+			i += size
+		case isDigit(src[i]):
+			end, err := scanNumber(src, i)
+			if err != nil {
+				return nil, err
+			}
+			tokens = append(tokens, token{kind: tokenNumber, text: src[i:end], pos: i})
+			i = end
+		case r == '"' || r == '\'':
+			s, end, err := scanString(src, i)
+			if err != nil {
+				return nil, err
+			}
+			tokens = append(tokens, token{kind: tokenString, text: s, pos: i})
+			i = end
+		case r == '_' || unicode.IsLetter(r):
+			end := i
+			for end < len(src) {
+				r, size := utf8.DecodeRuneInString(src[end:])
+				if r != '_' && !unicode.IsLetter(r) && !unicode.IsDigit(r) {
+					break
+				}
+				end += size
+			}
+			text := src[i:end]
+			if op, ok := keywords[text]; ok {
+				tokens = append(tokens, token{kind: tokenOp, text: op, pos: i})
+			} else {
+				tokens = append(tokens, token{kind: tokenIdent, text: text, pos: i})
+			}
+			i = end
+		default:
+			op := ""
+			for _, o := range twoCharOps {
+				if strings.HasPrefix(src[i:], o) {
+					op = o
+					break
+				}
+			}
+			if op == "" && strings.ContainsRune(singleCharOps, r) {
+				op = string(r)
+			}
+			if op == "" {
+				return nil, syntaxErrorf(i, "unexpected character %q", r)
+			}
+			tokens = append(tokens, token{kind: tokenOp, text: op, pos: i})
+			i += len(op)
+		}
+	}
+	return append(tokens, token{kind: tokenEOF, pos: len(src)}), nil
+}
+
+func isDigit(c byte) bool {
+	return c >= '0' && c <= '9'
+}
+
+// scanNumber returns the end of the integer or decimal number starting at i,
+// decimals need digits after the point and may have an exponent
+func scanNumber(src string, i int) (int, error) {
+	start := i
+	digits := func() int {
+		n := 0
+		for i < len(src) && isDigit(src[i]) {
+			i++
+			n++
+		}
+		return n
+	}
+	digits()
+	if i < len(src) && src[i] == '.' {
+		i++
+		if digits() == 0 {
+			return 0, syntaxErrorf(start, "invalid number %q", src[start:i])
+		}
+	}
+	if i < len(src) && (src[i] == 'e' || src[i] == 'E') {
+		i++
+		if i < len(src) && (src[i] == '+' || src[i] == '-') {
+			i++
+		}
+		if digits() == 0 {
+			return 0, syntaxErrorf(start, "invalid number %q", src[start:i])
+		}
+	}
+	return i, nil
+}
+
+// scanString returns the unescaped string quoted by the character at i and
+// the end of the string
+func scanString(src string, i int) (string, int, error) {
+	quote := src[i]
+	var b strings.Builder
+	for j := i + 1; j < len(src); j++ {
+		switch c := src[j]; c {
+		case quote:
+			return b.String(), j + 1, nil
+		case '\\':
+			j++
+			if j == len(src) {
+				break
+			}
+			switch src[j] {
+			case '\\', '"', '\'':
+				b.WriteByte(src[j])
+			case 'n':
+				b.WriteByte('\n')
+			case 't':
+				b.WriteByte('\t')
+			case 'r':
+				b.WriteByte('\r')
+			default:
+				return "", 0, syntaxErrorf(j-1, "invalid escape sequence \\%c", src[j])
+			}
+		default:
+			b.WriteByte(c)
+		}
+	}
+	return "", 0, syntaxErrorf(i, "unterminated string")
+}
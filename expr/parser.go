@@ -0,0 +1,238 @@
+package expr
+
+import (
+	"strconv"
+)
+
+// precedence of the binary operators, higher binds tighter
+var precedence = map[string]int{
+	"||": 1,
+	"&&": 2,
+	"==": 3, "!=": 3,
+	"<": 4, "<=": 4, ">": 4, ">=": 4, "in": 4,
+	"+": 5, "-": 5,
+	"*": 6, "/": 6, "%": 6,
+}
+
+type parser struct {
+	tokens []token
+	i      int
+	depth  int
+}
+
+func (p *parser) peek() token {
+	return p.tokens[p.i]
+}
+
+func (p *parser) next() token {
+	t := p.tokens[p.i]
+	if t.kind != tokenEOF {
+		p.i++
+	}
+	return t
+}
+
+// isOp reports whether the next token is the operator op
+func (p *parser) isOp(op string) bool {
+	t := p.peek()
+	return t.kind == tokenOp && t.text == op
+}
+
+func (p *parser) expect(op string) error {
+	if t := p.next(); t.kind != tokenOp || t.text != op {
+		return syntaxErrorf(t.pos, "expected `%s`, found %s", op, t)
+	}
+	return nil
+}
+
+func (p *parser) parse() (node, error) {
+	if p.peek().kind == tokenEOF {
+		return nil, syntaxErrorf(0, "empty expression")
+	}
+	n, err := p.parseBinary(1)
+	if err != nil {
+		return nil, err
+	}
+	if t := p.peek(); t.kind != tokenEOF {
+		return nil, syntaxErrorf(t.pos, "unexpected %s", t)
+	}
+	return n, nil
+}
+
+// enter limits the nesting of the expression, so parsing and evaluating it
+// can not exhaust the stack
+func (p *parser) enter(pos int) error {
+	p.depth++
+	if p.depth > maxDepth {
+		return syntaxErrorf(pos, "expression nested more than %d levels", maxDepth)
+	}
+	return nil
+}
+
+func (p *parser) parseBinary(minPrec int) (node, error) {
+	if err := p.enter(p.peek().pos); err != nil {
+		return nil, err
+	}
+	defer func() { p.depth-- }()
+	left, err := p.parseUnary()
+	if err != nil {
+		return nil, err
+	}
+	for {
+		t := p.peek()
+		prec, ok := precedence[t.text]
+		if t.kind != tokenOp || !ok || prec < minPrec {
+			return left, nil
+		}
+		p.next()
+		right, err := p.parseBinary(prec + 1)
+		if err != nil {
+			return nil, err
+		}
+		left = &binaryNode{op: t.text, left: left, right: right, pos: t.pos}
+	}
+}
+
+func (p *parser) parseUnary() (node, error) {
+	if t := p.peek(); t.kind == tokenOp && (t.text == "-" || t.text == "!") {
+		p.next()
+		if err := p.enter(t.pos); err != nil {
+			return nil, err
+		}
+		defer func() { p.depth-- }()
+		x, err := p.parseUnary()
+		if err != nil {
+			return nil, err
+		}
+		return &unaryNode{op: t.text, x: x, pos: t.pos}, nil
+	}
+	return p.parsePostfix()
+}
+
+func (p *parser) parsePostfix() (node, error) {
+	x, err := p.parsePrimary()
+	if err != nil {
+		return nil, err
+	}
+	for {
+		t := p.peek()
+		switch {
+		case p.isOp("."):
+			p.next()
+			name := p.next()
+			if name.kind != tokenIdent {
+				return nil, syntaxErrorf(name.pos, "expected field name, found %s", name)
+			}
+			x = &fieldNode{x: x, name: name.text, pos: name.pos}
+		case p.isOp("["):
+			p.next()
+			index, err := p.parseBinary(1)
+			if err != nil {
+				return nil, err
+			}
+			if err := p.expect("]"); err != nil {
+				return nil, err
+			}
+			x = &indexNode{x: x, index: index, pos: t.pos}
+		default:
+			return x, nil
+		}
+	}
+}
+
+func (p *parser) parsePrimary() (node, error) {
+	t := p.next()
+	switch t.kind {
+	case tokenNumber:
+		return parseNumber(t)
+	case tokenString:
+		return &literalNode{v: t.text}, nil
+	case tokenIdent:
+		switch t.text {
+		case "true":
+			return &literalNode{v: true}, nil
+		case "false":
+			return &literalNode{v: false}, nil
+		case "nil":
+			return &literalNode{v: nil}, nil
+		case "input":
+			return &inputNode{}, nil
+		}
+		if !p.isOp("(") {
+			return nil, syntaxErrorf(t.pos, "unknown identifier %s, the input is accessed with `input`", t)
+		}
+		return p.parseCall(t)
+	case tokenOp:
+		switch t.text {
+		case "(":
+			x, err := p.parseBinary(1)
+			if err != nil {
+				return nil, err
+			}
+			return x, p.expect(")")
+		case "[":
+			elems, err := p.parseList("]")
+			if err != nil {
+				return nil, err
+			}
+			return &listNode{elems: elems}, nil
+		}
+	}
+	return nil, syntaxErrorf(t.pos, "unexpected %s", t)
+}
+
+func (p *parser) parseCall(name token) (node, error) {
+	fn, ok := builtins[name.text]
+	if !ok {
+		return nil, syntaxErrorf(name.pos, "unknown function %s", name)
+	}
+	p.next()
+	args, err := p.parseList(")")
+	if err != nil {
+		return nil, err
+	}
+	if len(args) == 0 {
+		return nil, syntaxErrorf(name.pos, "function %s needs at least one argument", name)
+	}
+	return &callNode{name: name.text, fn: fn, args: args, pos: name.pos}, nil
+}
+
+// parseList parses the comma separated expressions until the closing operator
+func (p *parser) parseList(closing string) ([]node, error) {
+	var elems []node
+	for !p.isOp(closing) {
+		if len(elems) != 0 {
+			if err := p.expect(","); err != nil {
+				return nil, err
+			}
+		}
+		elem, err := p.parseBinary(1)
+		if err != nil {
+			return nil, err
+		}
+		elems = append(elems, elem)
+	}
+	p.next()
+	return elems, nil
+}
+
+// parseNumber parses an integer literal as int and a decimal one as float64
+func parseNumber(t token) (node, error) {
+	if i, err := strconv.ParseInt(t.text, 10, 0); err == nil {
+		return &literalNode{v: int(i)}, nil
+	}
+	f, err := strconv.ParseFloat(t.text, 64)
+	if err != nil || isInteger(t.text) {
+		return nil, syntaxErrorf(t.pos, "number %s out of range", t)
+	}
+	return &literalNode{v: f}, nil
+}
+
+func isInteger(s string) bool {
+	for i := 0; i < len(s); i++ {
+		if !isDigit(s[i]) {
+			return false
+		}
+	}
+	return true
+}
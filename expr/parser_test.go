@@ -0,0 +1,86 @@
+package expr
+
+import (
+	"errors"
+	"strings"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestCompile(t *testing.T) {
+	tests := map[string]struct {
+		source   string
+		expected interface{}
+	}{
+		"int":                {source: "42", expected: 42},
+		"float":              {source: "1.5", expected: 1.5},
+		"exponent":           {source: "2e3", expected: 2000.0},
+		"double quoted":      {source: `"a\"b\n"`, expected: "a\"b\n"},
+		"single quoted":      {source: `'it\'s'`, expected: "it's"},
+		"unicode string":     {source: `"ñandú"`, expected: "ñandú"},
+		"precedence":         {source: "1 + 2 * 3 - 4 / 2", expected: 5},
+		"parens":             {source: "(1 + 2) * 3", expected: 9},
+		"left associative":   {source: "10 - 4 - 3", expected: 3},
+		"unary":              {source: "- -3 + -(2)", expected: 1},
+		"comparison":         {source: "1 + 1 == 2 && 3 > 2", expected: true},
+		"keywords":           {source: "not false and (true or false)", expected: true},
+		"or before and":      {source: "true || false && false", expected: true},
+		"list":               {source: "[1, 'a', [true]]", expected: []interface{}{1, "a", []interface{}{true}}},
+		"empty list":         {source: "[]", expected: []interface{}{}},
+		"in":                 {source: "2 in [1, 2]", expected: true},
+		"call":               {source: "len('abc') + 1", expected: 4},
+		"nested calls":       {source: "upper(substring('hello', 1, 3))", expected: "EL"},
+		"nil":                {source: "nil == nil", expected: true},
+		"index of literal":   {source: "[10, 20][1]", expected: 20},
+		"whitespace":         {source: " \t1\n+\r2 ", expected: 3},
+		"identifier letters": {source: "input", expected: nil},
+	}
+	for name, tc := range tests {
+		t.Run(name, func(t *testing.T) {
+			p, err := Compile(tc.source)
+			require.NoError(t, err)
+			assert.Equal(t, tc.source, p.String())
+			res, err := p.Eval(nil)
+			require.NoError(t, err)
+			assert.Equal(t, tc.expected, res)
+		})
+	}
+}
+
+func TestCompile_SyntaxErrors(t *testing.T) {
+	tests := map[string]struct {
+		source   string
+		expected string
+	}{
+		"empty":              {source: "  ", expected: "syntax error at column 1: empty expression"},
+		"unexpected char":    {source: "1 # 2", expected: "syntax error at column 3: unexpected character '#'"},
+		"unterminated":       {source: `"abc`, expected: "syntax error at column 1: unterminated string"},
+		"escape":             {source: `'\d'`, expected: `syntax error at column 2: invalid escape sequence \d`},
+		"trailing point":     {source: "1.", expected: `syntax error at column 1: invalid number "1."`},
+		"exponent":           {source: "1e+", expected: `syntax error at column 1: invalid number "1e+"`},
+		"out of range":       {source: "99999999999999999999", expected: "syntax error at column 1: number `99999999999999999999` out of range"},
+		"missing operand":    {source: "1 +", expected: "syntax error at column 4: unexpected end of expression"},
+		"missing paren":      {source: "(1 + 2", expected: "syntax error at column 7: expected `)`, found end of expression"},
+		"extra token":        {source: "1 2", expected: "syntax error at column 3: unexpected `2`"},
+		"unknown identifier": {source: "age > 3", expected: "syntax error at column 1: unknown identifier `age`, the input is accessed with `input`"},
+		"unknown function":   {source: "exec('rm')", expected: "syntax error at column 1: unknown function `exec`"},
+		"no arguments":       {source: "len()", expected: "syntax error at column 1: function `len` needs at least one argument"},
+		"field name":         {source: "input.1", expected: "syntax error at column 7: expected field name, found `1`"},
+		"missing bracket":    {source: "input[0", expected: "syntax error at column 8: expected `]`, found end of expression"},
+		"trailing comma":     {source: "[1,]", expected: "syntax error at column 4: unexpected `]`"},
+		"list separator":     {source: "[1 2]", expected: "syntax error at column 4: expected `,`, found `2`"},
+		"keyword operand":    {source: "1 and", expected: "syntax error at column 6: unexpected end of expression"},
+		"too deep":           {source: strings.Repeat("(", 100) + "1" + strings.Repeat(")", 100), expected: "syntax error at column 65: expression nested more than 64 levels"},
+		"too many unary":     {source: strings.Repeat("-", 100) + "1", expected: "syntax error at column 64: expression nested more than 64 levels"},
+	}
+	for name, tc := range tests {
+		t.Run(name, func(t *testing.T) {
+			_, err := Compile(tc.source)
+			var syntaxErr *SyntaxError
+			require.True(t, errors.As(err, &syntaxErr), "%v", err)
+			assert.EqualError(t, err, tc.expected)
+		})
+	}
+}
@@ -0,0 +1,14 @@
+package ddt
+
+import (
+	"github.com/sgrodriguez/ddt/expr"
+)
+
+// expression returns the compiled expression of the node, the one compiled
+// by precompile unless the expression was modified since then
+func (n *Node) expression() (*expr.Program, error) {
+	if n.program != nil && n.program.String() == n.Expression {
+		return n.program, nil
+	}
+	return expr.Compile(n.Expression)
+}
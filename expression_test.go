@@ -0,0 +1,170 @@
+package ddt
+
+import (
+	"bytes"
+	"encoding/json"
+	"errors"
+	"sync"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"github.com/sgrodriguez/ddt/expr"
+	"github.com/sgrodriguez/ddt/function"
+	"github.com/sgrodriguez/ddt/value"
+)
+
+type subscriber struct {
+	Age    int
+	Months int
+	Orders []float64
+}
+
+const expressionTreeJSON = `{"name": "tenure", "root": {
+	"expression": "input.Age * 12 + input.Months",
+	"children": [
+		{"comparer": {"type": "lt"}, "valueToCompare": {"Value": 216, "Type": "int"}, "result": {"Value": "minor", "Type": "string"}},
+		{"default": true, "expression": "len(input.Orders) > 3 && sum(input.Orders) >= 100.0", "children": [
+			{"comparer": {"type": "eq"}, "valueToCompare": {"Value": true, "Type": "bool"}, "result": {"Value": "loyal", "Type": "string"}},
+			{"default": true, "result": {"Value": "adult", "Type": "string"}}
+		]}
+	]}}`
+
+func TestExpression_Resolve(t *testing.T) {
+	var tree Tree
+	require.NoError(t, json.Unmarshal([]byte(expressionTreeJSON), &tree))
+	evaluator, err := Compile(&tree)
+	require.NoError(t, err)
+	tests := map[string]struct {
+		input    interface{}
+		expected interface{}
+	}{
+		"minor":       {input: &subscriber{Age: 17, Months: 11}, expected: "minor"},
+		"adult":       {input: &subscriber{Age: 18}, expected: "adult"},
+		"few orders":  {input: &subscriber{Age: 30, Orders: []float64{50, 50, 50}}, expected: "adult"},
+		"loyal":       {input: &subscriber{Age: 30, Orders: []float64{50, 20, 20, 10}}, expected: "loyal"},
+		"map input":   {input: map[string]interface{}{"Age": 40, "Months": 1, "Orders": []int{100, 1, 1, 1}}, expected: "loyal"},
+		"cheap loyal": {input: subscriber{Age: 30, Orders: []float64{1, 1, 1, 1}}, expected: "adult"},
+	}
+	for name, tc := range tests {
+		t.Run(name, func(t *testing.T) {
+			res, err := ResolveTree(&tree, tc.input)
+			require.NoError(t, err)
+			assert.Equal(t, tc.expected, res)
+			res, err = evaluator.Resolve(tc.input)
+			require.NoError(t, err)
+			assert.Equal(t, tc.expected, res)
+		})
+	}
+	t.Run("errors", func(t *testing.T) {
+		input := map[string]interface{}{"Age": 40}
+		_, err := ResolveTree(&tree, input)
+		assert.True(t, errors.Is(err, ErrPreProcess))
+		var evalErr *expr.EvalError
+		assert.True(t, errors.As(err, &evalErr))
+		assert.EqualError(t, err, `node 0 (depth 0): expression "input.Age * 12 + input.Months" failed: column 24: key "Months" not found`)
+		_, compiledErr := evaluator.Resolve(input)
+		assert.Equal(t, err, compiledErr)
+	})
+	t.Run("trace", func(t *testing.T) {
+		_, trace, err := ExplainTree(&tree, &subscriber{Age: 30, Orders: []float64{50, 20, 20, 10}})
+		require.NoError(t, err)
+		assert.Equal(t, "input.Age * 12 + input.Months", trace.Steps[0].Expression)
+		assert.Equal(t, 360, trace.Steps[0].Value)
+		assert.Equal(t, true, trace.Steps[1].Value)
+	})
+}
+
+func TestExpression_JSON(t *testing.T) {
+	var tree Tree
+	require.NoError(t, json.Unmarshal([]byte(expressionTreeJSON), &tree))
+	assert.Equal(t, "input.Age * 12 + input.Months", tree.Root.Expression)
+	for name, marshal := range map[string]func() ([]byte, error){"flat": tree.MarshalJSON, "nested": tree.MarshalNestedJSON} {
+		b, err := marshal()
+		require.NoError(t, err, name)
+		assert.Contains(t, string(b), `"expression":"input.Age * 12 + input.Months"`, name)
+		var decoded Tree
+		require.NoError(t, json.Unmarshal(b, &decoded), name)
+		assert.Equal(t, tree.Root.Children[1].Expression, decoded.Root.Children[1].Expression, name)
+	}
+}
+
+func TestExpression_Validate(t *testing.T) {
+	leaf := &Node{ID: 1, ParentID: 0, Default: true, Result: &value.Value{Type: value.Int, Value: 1}}
+	tests := map[string]struct {
+		root     *Node
+		expected string
+	}{
+		"syntax error": {
+			root:     &Node{ID: 0, ParentID: -1, Expression: "input.Age *", Children: []*Node{leaf}},
+			expected: "invalid tree: node 0: invalid expression: syntax error at column 12: unexpected end of expression",
+		},
+		"unknown identifier": {
+			root:     &Node{ID: 0, ParentID: -1, Expression: "Age > 3", Children: []*Node{leaf}},
+			expected: "invalid tree: node 0: invalid expression: syntax error at column 1: unknown identifier `Age`, the input is accessed with `input`",
+		},
+		"with pre process function": {
+			root: &Node{ID: 0, ParentID: -1, Expression: "input", Children: []*Node{leaf},
+				PreProcessFn: function.PreProcessFn{Name: "Len", Function: function.Len}},
+			expected: "invalid tree: node 0: expression and pre process function or pipeline are mutually exclusive",
+		},
+	}
+	for name, tc := range tests {
+		t.Run(name, func(t *testing.T) {
+			_, err := NewTree("expressions", tc.root)
+			assert.EqualError(t, err, tc.expected)
+		})
+	}
+	t.Run("json", func(t *testing.T) {
+		err := json.Unmarshal([]byte(`{"root": {"children": [
+			{"default": true, "expression": "len(input", "children": [{"default": true, "result": {"Value": 1, "Type": "int"}}]}
+		]}}`), &Tree{})
+		assert.EqualError(t, err, "invalid tree: node 1: invalid expression: syntax error at column 10: expected `,`, found end of expression")
+	})
+	t.Run("compiled once", func(t *testing.T) {
+		var tree Tree
+		require.NoError(t, json.Unmarshal([]byte(expressionTreeJSON), &tree))
+		program := tree.Root.program
+		require.NotNil(t, program)
+		require.NoError(t, tree.Validate())
+		assert.Same(t, program, tree.Root.program)
+
+		tree.Root.Expression = "input.Age"
+		res, err := ResolveTree(&tree, &subscriber{Age: 17})
+		require.NoError(t, err)
+		assert.Equal(t, "minor", res)
+		require.NoError(t, tree.Validate())
+		assert.Same(t, program, tree.Root.program, "validating does not modify the tree")
+
+		created, err := NewTree("created", tree.Root)
+		require.NoError(t, err)
+		assert.Equal(t, "input.Age", created.Root.program.String())
+	})
+	t.Run("validated while resolving", func(t *testing.T) {
+		var tree Tree
+		require.NoError(t, json.Unmarshal([]byte(expressionTreeJSON), &tree))
+		var wg sync.WaitGroup
+		for i := 0; i < 4; i++ {
+			wg.Add(2)
+			go func() {
+				defer wg.Done()
+				assert.NoError(t, tree.Validate())
+			}()
+			go func() {
+				defer wg.Done()
+				_, err := ResolveTree(&tree, &subscriber{Age: 17})
+				assert.NoError(t, err)
+			}()
+		}
+		wg.Wait()
+	})
+}
+
+func TestExpression_Label(t *testing.T) {
+	var tree Tree
+	require.NoError(t, json.Unmarshal([]byte(expressionTreeJSON), &tree))
+	var buf bytes.Buffer
+	require.NoError(t, WriteMermaid(&buf, &tree))
+	assert.Contains(t, buf.String(), `n0["#0 input.Age * 12 + input.Months"]`)
+}
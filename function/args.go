@@ -0,0 +1,66 @@
+package function
+
+import (
+	"errors"
+	"fmt"
+
+	"github.com/sgrodriguez/ddt/compare"
+)
+
+// Errors returned by the standard library of pre process functions, they are
+// wrapped with the name of the function and the problem found
+var (
+	ErrInvalidArgs  = errors.New("invalid args")
+	ErrInvalidInput = errors.New("invalid input")
+)
+
+func argsError(fn, format string, a ...interface{}) error {
+	return fmt.Errorf("%s %w: %s", fn, ErrInvalidArgs, fmt.Sprintf(format, a...))
+}
+
+func inputError(fn string, input interface{}) error {
+	return fmt.Errorf("%s %w: %v (%T)", fn, ErrInvalidInput, input, input)
+}
+
+func overflowError(fn string, input interface{}) error {
+	return fmt.Errorf("%s %w: %v (%T) overflows", fn, ErrInvalidInput, input, input)
+}
+
+// checkArgs verifies the number of args is between min and max
+func checkArgs(fn string, args []interface{}, min, max int) error {
+	if len(args) < min || len(args) > max {
+		if min == max {
+			return argsError(fn, "expected %d args, got %d", min, len(args))
+		}
+		return argsError(fn, "expected %d to %d args, got %d", min, max, len(args))
+	}
+	return nil
+}
+
+func stringArg(fn string, args []interface{}, i int) (string, error) {
+	s, ok := args[i].(string)
+	if !ok {
+		return "", argsError(fn, "arg %d must be a string, got %T", i, args[i])
+	}
+	return s, nil
+}
+
+func intArg(fn string, args []interface{}, i int) (int, error) {
+	switch n := args[i].(type) {
+	case int:
+		return n, nil
+	case int64:
+		if int64(int(n)) == n {
+			return int(n), nil
+		}
+	}
+	return 0, argsError(fn, "arg %d must be an int, got %v (%T)", i, args[i], args[i])
+}
+
+func numberArg(fn string, args []interface{}, i int) (compare.Number, error) {
+	n, ok := compare.NumberOf(args[i])
+	if !ok {
+		return compare.Number{}, argsError(fn, "arg %d must be a number, got %v (%T)", i, args[i], args[i])
+	}
+	return n, nil
+}
@@ -0,0 +1,92 @@
+package function
+
+import (
+	"fmt"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/sgrodriguez/ddt/compare"
+	"github.com/sgrodriguez/ddt/value"
+)
+
+// ToInt converts the input number, or a string holding a number, to int
+func ToInt(str interface{}, args ...interface{}) (interface{}, error) {
+	return castInput("toInt", str, args, value.Int)
+}
+
+// ToInt64 converts the input number, or a string holding a number, to int64
+func ToInt64(str interface{}, args ...interface{}) (interface{}, error) {
+	return castInput("toInt64", str, args, value.Int64)
+}
+
+// ToFloat64 converts the input number, or a string holding a number, to float64
+func ToFloat64(str interface{}, args ...interface{}) (interface{}, error) {
+	return castInput("toFloat64", str, args, value.Float64)
+}
+
+func castInput(fn string, str interface{}, args []interface{}, t value.Type) (interface{}, error) {
+	if err := checkArgs(fn, args, 0, 0); err != nil {
+		return nil, err
+	}
+	if s, ok := str.(string); ok {
+		s = strings.TrimSpace(s)
+		if i, err := strconv.ParseInt(s, 10, 64); err == nil {
+			str = i
+		} else if f, err := strconv.ParseFloat(s, 64); err == nil {
+			str = f
+		} else {
+			return nil, inputError(fn, str)
+		}
+	}
+	res, err := CastNumber(str, t)
+	if err != nil {
+		return nil, fmt.Errorf("%s %w", fn, err)
+	}
+	return res, nil
+}
+
+// ToString formats the input value as a string, dates are formatted as RFC3339
+func ToString(str interface{}, args ...interface{}) (interface{}, error) {
+	if err := checkArgs("toString", args, 0, 0); err != nil {
+		return nil, err
+	}
+	switch v := str.(type) {
+	case string:
+		return v, nil
+	case float64:
+		return strconv.FormatFloat(v, 'f', -1, 64), nil
+	case float32:
+		return strconv.FormatFloat(float64(v), 'f', -1, 32), nil
+	case time.Time:
+		return v.Format(time.RFC3339), nil
+	case fmt.Stringer:
+		return v.String(), nil
+	case nil:
+		return nil, inputError("toString", str)
+	}
+	return fmt.Sprint(str), nil
+}
+
+// ToBool converts the input to bool, strings are parsed like strconv.ParseBool
+// and numbers are true when they are not zero
+func ToBool(str interface{}, args ...interface{}) (interface{}, error) {
+	if err := checkArgs("toBool", args, 0, 0); err != nil {
+		return nil, err
+	}
+	switch v := str.(type) {
+	case bool:
+		return v, nil
+	case string:
+		b, err := strconv.ParseBool(strings.TrimSpace(v))
+		if err != nil {
+			return nil, inputError("toBool", str)
+		}
+		return b, nil
+	}
+	n, ok := compare.NumberOf(str)
+	if !ok {
+		return nil, inputError("toBool", str)
+	}
+	return n.Float64() != 0, nil
+}
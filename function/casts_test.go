@@ -0,0 +1,55 @@
+package function
+
+import (
+	"testing"
+	"time"
+)
+
+func TestNumericCasts(t *testing.T) {
+	t.Parallel()
+	runPreProcessCases(t, ToInt, map[string]preProcessCase{
+		"float":        {input: 3.0, expected: 3},
+		"int64":        {input: int64(3), expected: 3},
+		"string":       {input: " 42 ", expected: 42},
+		"float string": {input: "42.0", expected: 42},
+		"not integer":  {input: 3.5, err: ErrInvalidCast},
+		"invalid text": {input: "abc", err: ErrInvalidInput},
+		"bool":         {input: true, err: ErrInvalidCast},
+		"with args":    {input: 1, args: []interface{}{1}, err: ErrInvalidArgs},
+	})
+	runPreProcessCases(t, ToInt64, map[string]preProcessCase{
+		"int":    {input: 3, expected: int64(3)},
+		"string": {input: "9007199254740993", expected: int64(9007199254740993)},
+	})
+	runPreProcessCases(t, ToFloat64, map[string]preProcessCase{
+		"int":    {input: 3, expected: 3.0},
+		"string": {input: "2.5", expected: 2.5},
+	})
+}
+
+func TestToString(t *testing.T) {
+	t.Parallel()
+	runPreProcessCases(t, ToString, map[string]preProcessCase{
+		"string":    {input: "a", expected: "a"},
+		"int":       {input: 12, expected: "12"},
+		"float":     {input: 0.1, expected: "0.1"},
+		"big float": {input: 1e21, expected: "1000000000000000000000"},
+		"bool":      {input: true, expected: "true"},
+		"time":      {input: time.Date(2020, 1, 2, 3, 4, 5, 0, time.UTC), expected: "2020-01-02T03:04:05Z"},
+		"stringer":  {input: time.Second, expected: "1s"},
+		"nil":       {input: nil, err: ErrInvalidInput},
+	})
+}
+
+func TestToBool(t *testing.T) {
+	t.Parallel()
+	runPreProcessCases(t, ToBool, map[string]preProcessCase{
+		"bool":         {input: false, expected: false},
+		"string":       {input: "true", expected: true},
+		"short string": {input: "0", expected: false},
+		"number":       {input: 2.5, expected: true},
+		"zero":         {input: 0, expected: false},
+		"invalid text": {input: "yes", err: ErrInvalidInput},
+		"slice":        {input: []bool{true}, err: ErrInvalidInput},
+	})
+}
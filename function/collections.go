@@ -0,0 +1,160 @@
+package function
+
+import (
+	"reflect"
+	"strings"
+	"unicode/utf8"
+
+	"github.com/sgrodriguez/ddt/compare"
+)
+
+// Len returns the number of elements of the input slice, array or map, or the
+// number of characters of a string
+func Len(str interface{}, args ...interface{}) (interface{}, error) {
+	if err := checkArgs("len", args, 0, 0); err != nil {
+		return nil, err
+	}
+	if s, ok := str.(string); ok {
+		return utf8.RuneCountInString(s), nil
+	}
+	v := reflect.ValueOf(str)
+	switch v.Kind() {
+	case reflect.Slice, reflect.Array, reflect.Map:
+		return v.Len(), nil
+	}
+	return nil, inputError("len", str)
+}
+
+// Contains reports whether the input slice or array has an element equal to
+// the arg, whether the input map has the arg as key, or whether the input
+// string contains the arg substring. Numbers are equal when they have the
+// same value even if their types differ.
+func Contains(str interface{}, args ...interface{}) (interface{}, error) {
+	if err := checkArgs("contains", args, 1, 1); err != nil {
+		return nil, err
+	}
+	if s, ok := str.(string); ok {
+		sub, err := stringArg("contains", args, 0)
+		if err != nil {
+			return nil, err
+		}
+		return strings.Contains(s, sub), nil
+	}
+	v := reflect.ValueOf(str)
+	switch v.Kind() {
+	case reflect.Slice, reflect.Array:
+		for i := 0; i < v.Len(); i++ {
+			if equalValues(v.Index(i).Interface(), args[0]) {
+				return true, nil
+			}
+		}
+		return false, nil
+	case reflect.Map:
+		for _, k := range v.MapKeys() {
+			if equalValues(k.Interface(), args[0]) {
+				return true, nil
+			}
+		}
+		return false, nil
+	}
+	return nil, inputError("contains", str)
+}
+
+// Sum returns the sum of the numbers of the input slice or array, as an int
+// when all of them are integers and as a float64 otherwise
+func Sum(str interface{}, args ...interface{}) (interface{}, error) {
+	if err := checkArgs("sum", args, 0, 0); err != nil {
+		return nil, err
+	}
+	numbers, err := numbersInput("sum", str)
+	if err != nil {
+		return nil, err
+	}
+	var f float64
+	var i int64
+	isInt, overflow := true, false
+	for _, n := range numbers {
+		ni, ok := n.Int64()
+		if !ok {
+			isInt = false
+		}
+		f += n.Float64()
+		if isInt && !overflow {
+			i, ok = addInt64(i, ni)
+			overflow = !ok
+		}
+	}
+	if !isInt {
+		return f, nil
+	}
+	if overflow || int64(int(i)) != i {
+		return nil, overflowError("sum", str)
+	}
+	return int(i), nil
+}
+
+// Min returns the smallest number of the input slice or array
+func Min(str interface{}, args ...interface{}) (interface{}, error) {
+	return extreme("min", str, args, -1)
+}
+
+// Max returns the greatest number of the input slice or array
+func Max(str interface{}, args ...interface{}) (interface{}, error) {
+	return extreme("max", str, args, 1)
+}
+
+// extreme returns the element with the smallest (sign -1) or greatest (sign 1)
+// value, keeping its type
+func extreme(fn string, str interface{}, args []interface{}, sign int) (interface{}, error) {
+	if err := checkArgs(fn, args, 0, 0); err != nil {
+		return nil, err
+	}
+	numbers, err := numbersInput(fn, str)
+	if err != nil {
+		return nil, err
+	}
+	if len(numbers) == 0 {
+		return nil, inputError(fn, str)
+	}
+	best := 0
+	for i, n := range numbers[1:] {
+		if c, _ := n.Compare(numbers[best]); c*sign > 0 {
+			best = i + 1
+		}
+	}
+	return reflect.ValueOf(str).Index(best).Interface(), nil
+}
+
+func numbersInput(fn string, str interface{}) ([]compare.Number, error) {
+	v := reflect.ValueOf(str)
+	if v.Kind() != reflect.Slice && v.Kind() != reflect.Array {
+		return nil, inputError(fn, str)
+	}
+	numbers := make([]compare.Number, v.Len())
+	for i := range numbers {
+		n, ok := compare.NumberOf(v.Index(i).Interface())
+		if !ok {
+			return nil, inputError(fn, str)
+		}
+		numbers[i] = n
+	}
+	return numbers, nil
+}
+
+// equalValues compares numbers by value and any other value with ==
+func equalValues(a, b interface{}) bool {
+	an, aok := compare.NumberOf(a)
+	bn, bok := compare.NumberOf(b)
+	if aok && bok {
+		c, ok := an.Compare(bn)
+		return ok && c == 0
+	}
+	if aok || bok {
+		return false
+	}
+	ta := reflect.TypeOf(a)
+	if ta == nil || !ta.Comparable() || ta != reflect.TypeOf(b) {
+		return a == nil && b == nil
+	}
+	return a == b
+}
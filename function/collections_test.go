@@ -0,0 +1,58 @@
+package function
+
+import (
+	"math"
+	"testing"
+)
+
+func TestLen(t *testing.T) {
+	t.Parallel()
+	runPreProcessCases(t, Len, map[string]preProcessCase{
+		"slice":     {input: []interface{}{1, "a"}, expected: 2},
+		"array":     {input: [3]int{}, expected: 3},
+		"map":       {input: map[string]int{"a": 1}, expected: 1},
+		"string":    {input: "ñu", expected: 2},
+		"not sized": {input: 10, err: ErrInvalidInput},
+		"with args": {input: "a", args: []interface{}{1}, err: ErrInvalidArgs},
+	})
+}
+
+func TestContains(t *testing.T) {
+	t.Parallel()
+	runPreProcessCases(t, Contains, map[string]preProcessCase{
+		"string element":        {input: []string{"vip", "new"}, args: []interface{}{"vip"}, expected: true},
+		"missing element":       {input: []string{"vip", "new"}, args: []interface{}{"old"}, expected: false},
+		"number of other type":  {input: []interface{}{1.0, 2.0}, args: []interface{}{2}, expected: true},
+		"number and string":     {input: []interface{}{"2"}, args: []interface{}{2}, expected: false},
+		"not comparable values": {input: []interface{}{[]int{1}}, args: []interface{}{"a"}, expected: false},
+		"map key":               {input: map[string]bool{"a": true}, args: []interface{}{"a"}, expected: true},
+		"substring":             {input: "hello", args: []interface{}{"ell"}, expected: true},
+		"substring not string":  {input: "hello", args: []interface{}{1}, err: ErrInvalidArgs},
+		"not collection":        {input: 1, args: []interface{}{1}, err: ErrInvalidInput},
+		"missing arg":           {input: []int{}, err: ErrInvalidArgs},
+	})
+}
+
+func TestSumMinMax(t *testing.T) {
+	t.Parallel()
+	runPreProcessCases(t, Sum, map[string]preProcessCase{
+		"ints":       {input: []int{1, 2, 3}, expected: 6},
+		"mixed":      {input: []interface{}{1, 2.5, int64(3)}, expected: 6.5},
+		"empty":      {input: []float64{}, expected: 0},
+		"not number": {input: []interface{}{1, "2"}, err: ErrInvalidInput},
+		"not slice":  {input: 1, err: ErrInvalidInput},
+		"overflow":   {input: []int64{math.MaxInt64, 1}, err: ErrInvalidInput},
+		"underflow":  {input: []int{math.MinInt64, -1}, err: ErrInvalidInput},
+		"big floats": {input: []interface{}{math.MaxInt64, 1, 0.5}, expected: float64(math.MaxInt64) + 1.5},
+	})
+	runPreProcessCases(t, Min, map[string]preProcessCase{
+		"ints":  {input: []int{3, -1, 2}, expected: -1},
+		"mixed": {input: []interface{}{2, 1.5, int64(7)}, expected: 1.5},
+		"empty": {input: []int{}, err: ErrInvalidInput},
+	})
+	runPreProcessCases(t, Max, map[string]preProcessCase{
+		"ints":  {input: []int64{3, -1, 2}, expected: int64(3)},
+		"mixed": {input: []interface{}{2, 1.5, int64(7)}, expected: int64(7)},
+		"first": {input: []interface{}{2, 2.0}, expected: 2},
+	})
+}
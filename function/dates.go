@@ -0,0 +1,119 @@
+package function
+
+import (
+	"sync"
+	"time"
+)
+
+// Now returns the current time used by the date functions, it can be replaced
+// in tests
+var Now = time.Now
+
+// Age returns the number of whole years elapsed since the input date
+func Age(str interface{}, args ...interface{}) (interface{}, error) {
+	if err := checkArgs("age", args, 0, 0); err != nil {
+		return nil, err
+	}
+	birth, err := timeInput("age", str)
+	if err != nil {
+		return nil, err
+	}
+	now := Now().In(birth.Location())
+	years := now.Year() - birth.Year()
+	if now.Month() < birth.Month() || (now.Month() == birth.Month() && now.Day() < birth.Day()) {
+		years--
+	}
+	return years, nil
+}
+
+// DayOfWeek returns the english name of the day of the week of the input date
+// (ie "Monday"), in the location of the date or the optional location arg
+func DayOfWeek(str interface{}, args ...interface{}) (interface{}, error) {
+	t, err := timeInLocation("dayOfWeek", str, args)
+	if err != nil {
+		return nil, err
+	}
+	return t.Weekday().String(), nil
+}
+
+// Hour returns the hour (0-23) of the input date, in the location of the date
+// or the optional location arg
+func Hour(str interface{}, args ...interface{}) (interface{}, error) {
+	t, err := timeInLocation("hour", str, args)
+	if err != nil {
+		return nil, err
+	}
+	return t.Hour(), nil
+}
+
+func timeInLocation(fn string, str interface{}, args []interface{}) (time.Time, error) {
+	loc, err := locationArgs(fn, args)
+	if err != nil {
+		return time.Time{}, err
+	}
+	t, err := timeInput(fn, str)
+	if err != nil {
+		return time.Time{}, err
+	}
+	if loc == nil {
+		return t, nil
+	}
+	return t.In(loc), nil
+}
+
+// locationArgs returns the optional location arg, nil when it is not given
+func locationArgs(fn string, args []interface{}) (*time.Location, error) {
+	if err := checkArgs(fn, args, 0, 1); err != nil {
+		return nil, err
+	}
+	if len(args) == 0 {
+		return nil, nil
+	}
+	name, err := stringArg(fn, args, 0)
+	if err != nil {
+		return nil, err
+	}
+	loc, err := loadLocation(name)
+	if err != nil {
+		return nil, argsError(fn, "%v", err)
+	}
+	return loc, nil
+}
+
+// locations caches the loaded locations by name, only valid names are stored
+// so it is bounded by the time zone database
+var locations sync.Map
+
+// loadLocation loads a location once, time.LoadLocation reads the time zone
+// database on every call
+func loadLocation(name string) (*time.Location, error) {
+	if loc, ok := locations.Load(name); ok {
+		return loc.(*time.Location), nil
+	}
+	loc, err := time.LoadLocation(name)
+	if err != nil {
+		return nil, err
+	}
+	locations.Store(name, loc)
+	return loc, nil
+}
+
+// timeInput accepts a time.Time or a string in RFC3339 or "2006-01-02" format
+func timeInput(fn string, str interface{}) (time.Time, error) {
+	switch t := str.(type) {
+	case time.Time:
+		return t, nil
+	case *time.Time:
+		if t != nil {
+			return *t, nil
+		}
+	case string:
+		if parsed, err := time.Parse(time.RFC3339, t); err == nil {
+			return parsed, nil
+		}
+		if parsed, err := time.Parse("2006-01-02", t); err == nil {
+			return parsed, nil
+		}
+	}
+	return time.Time{}, inputError(fn, str)
+}
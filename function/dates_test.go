@@ -0,0 +1,50 @@
+package function
+
+import (
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestAge(t *testing.T) {
+	now := Now
+	defer func() { Now = now }()
+	Now = func() time.Time { return time.Date(2020, time.June, 15, 10, 0, 0, 0, time.UTC) }
+	birth := time.Date(1990, time.June, 16, 0, 0, 0, 0, time.UTC)
+	runPreProcessCases(t, Age, map[string]preProcessCase{
+		"birthday not reached": {input: birth, expected: 29},
+		"birthday today":       {input: "1990-06-15", expected: 30},
+		"pointer":              {input: &birth, expected: 29},
+		"rfc3339":              {input: "2000-01-01T00:00:00Z", expected: 20},
+		"invalid date":         {input: "15/06/1990", err: ErrInvalidInput},
+		"nil pointer":          {input: (*time.Time)(nil), err: ErrInvalidInput},
+		"with args":            {input: birth, args: []interface{}{1}, err: ErrInvalidArgs},
+	})
+}
+
+func TestDayOfWeekAndHour(t *testing.T) {
+	t.Parallel()
+	date := time.Date(2020, time.June, 15, 23, 30, 0, 0, time.UTC)
+	runPreProcessCases(t, DayOfWeek, map[string]preProcessCase{
+		"utc":              {input: date, expected: "Monday"},
+		"location":         {input: date, args: []interface{}{"Asia/Tokyo"}, expected: "Tuesday"},
+		"string":           {input: "2020-06-13", expected: "Saturday"},
+		"unknown location": {input: date, args: []interface{}{"Nowhere/City"}, err: ErrInvalidArgs},
+		"not date":         {input: 1, err: ErrInvalidInput},
+	})
+	runPreProcessCases(t, Hour, map[string]preProcessCase{
+		"utc":      {input: date, expected: 23},
+		"location": {input: "2020-06-15T23:30:00Z", args: []interface{}{"America/Montevideo"}, expected: 20},
+	})
+	t.Run("cached location", func(t *testing.T) {
+		_, err := Hour(date, "Europe/Madrid")
+		require.NoError(t, err)
+		first, _ := loadLocation("Europe/Madrid")
+		second, _ := loadLocation("Europe/Madrid")
+		assert.True(t, first == second)
+		_, cached := locations.Load("Nowhere/City")
+		assert.False(t, cached)
+	})
+}
@@ -1,14 +1,29 @@
 package function
 
 import (
+	"context"
 	"errors"
+	"fmt"
 	"reflect"
 )
 
+// Errors returned by the default pre process functions
+var (
+	ErrInvalidMethodArgs      = errors.New("callStructMethod invalid methods args")
+	ErrEmptyMethodResult      = errors.New("callStructMethod empty result from method call")
+	ErrMethodResultInterface  = errors.New("callStructMethod can not interface result value")
+	ErrInvalidAttributeArgs   = errors.New("getStructAttribute invalid args")
+	ErrInvalidStructAttribute = errors.New("getStructAttribute invalid struct attribute")
+	ErrNilStructAttribute     = errors.New("getStructAttribute nil attribute")
+)
+
 // PreProcessFn ..
 type PreProcessFn struct {
 	Function func(str interface{}, args ...interface{}) (interface{}, error)
-	Name     string
+	// ContextFunction is called instead of Function when defined, it receives
+	// the context of the resolution so long running functions can be aborted.
+	ContextFunction func(ctx context.Context, str interface{}, args ...interface{}) (interface{}, error)
+	Name            string
 }
 
 // Empty ..
@@ -16,14 +31,27 @@ func (p PreProcessFn) Empty() bool {
 	return p.Name == ""
 }
 
+// Defined reports whether the function has an implementation
+func (p PreProcessFn) Defined() bool {
+	return p.Function != nil || p.ContextFunction != nil
+}
+
+// Call calls ContextFunction when defined, otherwise Function
+func (p PreProcessFn) Call(ctx context.Context, str interface{}, args ...interface{}) (interface{}, error) {
+	if p.ContextFunction != nil {
+		return p.ContextFunction(ctx, str, args...)
+	}
+	return p.Function(str, args...)
+}
+
 // CallStructMethod ... explain better what is
 func CallStructMethod(str interface{}, args ...interface{}) (interface{}, error) {
 	if len(args) < 1 || str == nil {
-		return nil, errors.New("callStructMethod invalid methods args")
+		return nil, ErrInvalidMethodArgs
 	}
 	methodName, ok := args[0].(string)
 	if !ok {
-		return nil, errors.New("callStructMethod invalid methods args")
+		return nil, ErrInvalidMethodArgs
 	}
 	inputs := make([]reflect.Value, len(args)-1)
 	// avoid first value of args
@@ -32,11 +60,11 @@ func CallStructMethod(str interface{}, args ...interface{}) (interface{}, error)
 	}
 	method := reflect.ValueOf(str).MethodByName(methodName)
 	if !method.IsValid() {
-		return nil, errors.New("callStructMethod invalid methods args")
+		return nil, ErrInvalidMethodArgs
 	}
 	res := method.Call(inputs)
 	if len(res) == 0 {
-		return nil, errors.New("callStructMethod empty result from method call")
+		return nil, ErrEmptyMethodResult
 	}
 	errorInterface := reflect.TypeOf((*error)(nil)).Elem()
 	for _, r := range res {
@@ -47,24 +75,88 @@ func CallStructMethod(str interface{}, args ...interface{}) (interface{}, error)
 		}
 	}
 	if !res[0].CanInterface() {
-		return nil, errors.New("callStructMethod can not interface result value")
+		return nil, ErrMethodResultInterface
 	}
 	return res[0].Interface(), nil
 }
 
-// GetStructAttribute ...  explain better what is
+// GetStructAttribute returns the value of a struct field given its name or a
+// path like "Address.Country.Code". The path goes through pointers,
+// interfaces, maps with string keys, slice and array indices (ie
+// "Orders[0].Items[1]") and fields promoted from embedded structs. When a nil
+// pointer, nil map or missing map key is found in the path an
+// ErrNilStructAttribute is returned, unless a second arg is given which is
+// returned instead.
 func GetStructAttribute(str interface{}, args ...interface{}) (interface{}, error) {
-	if len(args) < 1 || str == nil {
-		return nil, errors.New("getStructAttribute invalid args")
+	if len(args) < 1 || len(args) > 2 || str == nil {
+		return nil, ErrInvalidAttributeArgs
 	}
-	prop, ok := args[0].(string)
+	path, ok := args[0].(string)
 	if !ok {
-		return nil, errors.New("getStructAttribute invalid args")
+		return nil, ErrInvalidAttributeArgs
+	}
+	segments, err := parsePath(path)
+	if err != nil {
+		return nil, fmt.Errorf("getStructAttribute %w %q", err, path)
+	}
+	val := reflect.ValueOf(str)
+	for i, s := range segments {
+		val, err = attribute(val, s)
+		if err == ErrNilStructAttribute && len(args) == 2 {
+			return args[1], nil
+		}
+		if err != nil {
+			return nil, fmt.Errorf("%w: %s", err, joinPath(segments, i+1))
+		}
 	}
-	r := reflect.ValueOf(str)
-	val := reflect.Indirect(r).FieldByName(prop)
-	if !val.IsValid() || !val.CanInterface() {
-		return nil, errors.New("getStructAttribute invalid struct attribute")
+	if !val.CanInterface() {
+		return nil, fmt.Errorf("%w: %s", ErrInvalidStructAttribute, path)
 	}
 	return val.Interface(), nil
 }
+
+// attribute returns the field, map value or element of v given by s
+func attribute(v reflect.Value, s pathSegment) (reflect.Value, error) {
+	for v.Kind() == reflect.Ptr || v.Kind() == reflect.Interface {
+		if v.IsNil() {
+			return reflect.Value{}, ErrNilStructAttribute
+		}
+		v = v.Elem()
+	}
+	if s.isIndex {
+		if (v.Kind() != reflect.Slice && v.Kind() != reflect.Array) || s.index >= v.Len() {
+			return reflect.Value{}, ErrInvalidStructAttribute
+		}
+		return v.Index(s.index), nil
+	}
+	switch v.Kind() {
+	case reflect.Struct:
+		field, ok := v.Type().FieldByName(s.key)
+		if !ok || field.PkgPath != "" {
+			return reflect.Value{}, ErrInvalidStructAttribute
+		}
+		// promoted fields of embedded struct pointers can not use FieldByIndex
+		for i, index := range field.Index {
+			if i != 0 {
+				for v.Kind() == reflect.Ptr {
+					if v.IsNil() {
+						return reflect.Value{}, ErrNilStructAttribute
+					}
+					v = v.Elem()
+				}
+			}
+			v = v.Field(index)
+		}
+		return v, nil
+	case reflect.Map:
+		if v.Type().Key().Kind() != reflect.String {
+			return reflect.Value{}, ErrInvalidStructAttribute
+		}
+		val := v.MapIndex(reflect.ValueOf(s.key).Convert(v.Type().Key()))
+		if !val.IsValid() {
+			return reflect.Value{}, ErrNilStructAttribute
+		}
+		return val, nil
+	}
+	return reflect.Value{}, ErrInvalidStructAttribute
+}
@@ -1,6 +1,7 @@
 package function
 
 import (
+	"context"
 	"errors"
 	"reflect"
 	"testing"
@@ -152,3 +153,119 @@ func TestPreProcessFnEmpty(t *testing.T) {
 	p.Name = "Hi"
 	assert.False(t, p.Empty(), "preprocessFnEmpty not empty name")
 }
+
+func TestPreProcessFnCall(t *testing.T) {
+	t.Parallel()
+	p := PreProcessFn{Name: "Hi"}
+	assert.False(t, p.Defined(), "preprocessFn without implementation")
+	p.Function = func(str interface{}, args ...interface{}) (interface{}, error) {
+		return "function", nil
+	}
+	assert.True(t, p.Defined(), "preprocessFn with function")
+	val, err := p.Call(context.Background(), nil)
+	assert.NoError(t, err)
+	assert.Equal(t, "function", val)
+	p.ContextFunction = func(ctx context.Context, str interface{}, args ...interface{}) (interface{}, error) {
+		return ctx.Value(ctxKey{}), nil
+	}
+	val, err = p.Call(context.WithValue(context.Background(), ctxKey{}, "context function"), nil)
+	assert.NoError(t, err)
+	assert.Equal(t, "context function", val)
+}
+
+type ctxKey struct{}
+
+type testCountry struct {
+	Code string
+}
+
+type testAddress struct {
+	Street  string
+	Country *testCountry
+}
+
+type Audit struct {
+	CreatedBy string
+}
+
+type testCustomer struct {
+	*Audit
+	Name      string
+	Address   testAddress
+	Previous  *testAddress
+	Tags      []string
+	Scores    [2]int
+	Labels    map[string]string
+	Extra     interface{}
+	Addresses []*testAddress
+}
+
+func TestStructAttributePath(t *testing.T) {
+	t.Parallel()
+	c := &testCustomer{
+		Audit:     &Audit{CreatedBy: "admin"},
+		Name:      "ana",
+		Address:   testAddress{Street: "Main", Country: &testCountry{Code: "UY"}},
+		Tags:      []string{"vip", "new"},
+		Scores:    [2]int{7, 9},
+		Labels:    map[string]string{"tier": "gold"},
+		Extra:     map[string]interface{}{"source": "web"},
+		Addresses: []*testAddress{{Street: "Second"}, nil},
+	}
+	tests := map[string]struct {
+		args     []interface{}
+		expected interface{}
+	}{
+		"nested struct":           {args: []interface{}{"Address.Street"}, expected: "Main"},
+		"through pointer":         {args: []interface{}{"Address.Country.Code"}, expected: "UY"},
+		"slice index":             {args: []interface{}{"Tags[1]"}, expected: "new"},
+		"array index":             {args: []interface{}{"Scores[0]"}, expected: 7},
+		"map key":                 {args: []interface{}{"Labels.tier"}, expected: "gold"},
+		"interface map key":       {args: []interface{}{"Extra.source"}, expected: "web"},
+		"slice of pointers":       {args: []interface{}{"Addresses[0].Street"}, expected: "Second"},
+		"promoted field":          {args: []interface{}{"CreatedBy"}, expected: "admin"},
+		"embedded struct":         {args: []interface{}{"Audit.CreatedBy"}, expected: "admin"},
+		"nil pointer value":       {args: []interface{}{"Previous"}, expected: (*testAddress)(nil)},
+		"nil pointer default":     {args: []interface{}{"Previous.Country.Code", "none"}, expected: "none"},
+		"nil element default":     {args: []interface{}{"Addresses[1].Street", ""}, expected: ""},
+		"missing map key default": {args: []interface{}{"Labels.region", "unknown"}, expected: "unknown"},
+	}
+	for name, tc := range tests {
+		t.Run(name, func(t *testing.T) {
+			got, err := GetStructAttribute(c, tc.args...)
+			assert.NoError(t, err)
+			assert.Equal(t, tc.expected, got)
+		})
+	}
+	testErrors := map[string]struct {
+		path string
+		err  error
+		msg  string
+	}{
+		"nil pointer":        {path: "Previous.Street", err: ErrNilStructAttribute, msg: "getStructAttribute nil attribute: Previous.Street"},
+		"nil slice element":  {path: "Addresses[1].Street", err: ErrNilStructAttribute, msg: "getStructAttribute nil attribute: Addresses[1].Street"},
+		"missing map key":    {path: "Labels.region", err: ErrNilStructAttribute, msg: "getStructAttribute nil attribute: Labels.region"},
+		"unknown field":      {path: "Address.Zip", err: ErrInvalidStructAttribute, msg: "getStructAttribute invalid struct attribute: Address.Zip"},
+		"index out of range": {path: "Tags[2]", err: ErrInvalidStructAttribute, msg: "getStructAttribute invalid struct attribute: Tags[2]"},
+		"index over struct":  {path: "Address[0]", err: ErrInvalidStructAttribute},
+		"key over string":    {path: "Name.First", err: ErrInvalidStructAttribute},
+		"invalid path":       {path: "Tags[x]", err: ErrInvalidPath},
+	}
+	for name, tc := range testErrors {
+		t.Run(name, func(t *testing.T) {
+			_, err := GetStructAttribute(c, tc.path)
+			assert.True(t, errors.Is(err, tc.err), "unexpected error %v", err)
+			if tc.msg != "" {
+				assert.EqualError(t, err, tc.msg)
+			}
+		})
+	}
+	t.Run("nil embedded struct", func(t *testing.T) {
+		_, err := GetStructAttribute(&testCustomer{}, "CreatedBy")
+		assert.True(t, errors.Is(err, ErrNilStructAttribute))
+	})
+	t.Run("too many args", func(t *testing.T) {
+		_, err := GetStructAttribute(c, "Name", "", "")
+		assert.Equal(t, ErrInvalidAttributeArgs, err)
+	})
+}
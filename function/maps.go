@@ -0,0 +1,132 @@
+package function
+
+import (
+	"encoding/json"
+	"errors"
+	"fmt"
+	"math"
+
+	"github.com/sgrodriguez/ddt/compare"
+	"github.com/sgrodriguez/ddt/value"
+)
+
+// Errors returned by GetMapValue
+var (
+	ErrInvalidMapArgs = errors.New("getMapValue invalid args")
+	ErrMapKeyNotFound = errors.New("getMapValue key not found")
+	ErrInvalidCast    = errors.New("invalid numeric cast")
+)
+
+// GetMapValue returns the value of a path of keys in a map[string]interface{},
+// like the ones decoded from json. The path is made of keys separated by dots
+// and array indices (ie "user.orders[0].amount"). The json numbers are
+// normalized like NormalizeJSON, the optional second arg is a value type
+// (value.Int, value.Int64 or value.Float64) to convert the numeric result to,
+// so it matches the type of the values to compare.
+func GetMapValue(str interface{}, args ...interface{}) (interface{}, error) {
+	if len(args) < 1 || len(args) > 2 || str == nil {
+		return nil, ErrInvalidMapArgs
+	}
+	path, ok := args[0].(string)
+	if !ok {
+		return nil, ErrInvalidMapArgs
+	}
+	var cast value.Type
+	if len(args) == 2 {
+		t, ok := args[1].(string)
+		if !ok {
+			return nil, ErrInvalidMapArgs
+		}
+		cast = value.Type(t)
+	}
+	segments, err := parsePath(path)
+	if err != nil {
+		return nil, fmt.Errorf("getMapValue %w %q", err, path)
+	}
+	current := str
+	for i, s := range segments {
+		switch v := current.(type) {
+		case map[string]interface{}:
+			if s.isIndex {
+				return nil, fmt.Errorf("%w: %s is not an array", ErrMapKeyNotFound, joinPath(segments, i))
+			}
+			val, ok := v[s.key]
+			if !ok {
+				return nil, fmt.Errorf("%w: %s", ErrMapKeyNotFound, joinPath(segments, i+1))
+			}
+			current = val
+		case []interface{}:
+			if !s.isIndex || s.index >= len(v) {
+				return nil, fmt.Errorf("%w: %s", ErrMapKeyNotFound, joinPath(segments, i+1))
+			}
+			current = v[s.index]
+		default:
+			return nil, fmt.Errorf("%w: %s is not an object or array", ErrMapKeyNotFound, joinPath(segments, i))
+		}
+	}
+	current = NormalizeJSON(current)
+	if cast != "" {
+		return CastNumber(current, cast)
+	}
+	return current, nil
+}
+
+// NormalizeJSON converts the json.Number values (decoded with UseNumber) of
+// maps and slices to int when they are integers fitting an int, and to
+// float64 otherwise. The maps and slices are copied, v is never modified so
+// it can be shared between goroutines.
+func NormalizeJSON(v interface{}) interface{} {
+	switch val := v.(type) {
+	case json.Number:
+		if i, err := val.Int64(); err == nil && int64(int(i)) == i {
+			return int(i)
+		}
+		f, _ := val.Float64()
+		return f
+	case map[string]interface{}:
+		m := make(map[string]interface{}, len(val))
+		for k, e := range val {
+			m[k] = NormalizeJSON(e)
+		}
+		return m
+	case []interface{}:
+		l := make([]interface{}, len(val))
+		for i, e := range val {
+			l[i] = NormalizeJSON(e)
+		}
+		return l
+	}
+	return v
+}
+
+// CastNumber converts a numeric value (any int, uint or float kind or a
+// json.Number) to the given value type: value.Int, value.Int64 or
+// value.Float64. Converting a non integer or out of range value to an integer
+// type fails.
+func CastNumber(v interface{}, t value.Type) (interface{}, error) {
+	n, ok := compare.NumberOf(v)
+	if !ok {
+		return nil, fmt.Errorf("%w: %v is not a number", ErrInvalidCast, v)
+	}
+	i, isInt := n.Int64()
+	switch t {
+	case value.Float64:
+		return n.Float64(), nil
+	case value.Int, value.Int64:
+		if !isInt {
+			f := n.Float64()
+			if f != math.Trunc(f) || f < math.MinInt64 || f >= math.MaxInt64 {
+				return nil, fmt.Errorf("%w: %v is not an %s", ErrInvalidCast, v, t)
+			}
+			i = int64(f)
+		}
+		if t == value.Int64 {
+			return i, nil
+		}
+		if int64(int(i)) != i {
+			return nil, fmt.Errorf("%w: %v overflows int", ErrInvalidCast, v)
+		}
+		return int(i), nil
+	}
+	return nil, fmt.Errorf("%w: invalid numeric type %q", ErrInvalidCast, t)
+}
@@ -0,0 +1,130 @@
+package function
+
+import (
+	"encoding/json"
+	"errors"
+	"strings"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func decodeJSON(t *testing.T, data string) interface{} {
+	var v interface{}
+	dec := json.NewDecoder(strings.NewReader(data))
+	dec.UseNumber()
+	require.NoError(t, dec.Decode(&v))
+	return v
+}
+
+func TestGetMapValue(t *testing.T) {
+	t.Parallel()
+	doc := decodeJSON(t, `{
+		"user": {"name": "ana", "age": 31, "score": 4.5, "active": true},
+		"orders": [{"amount": 120}, {"amount": 15.5, "items": [[1, 2], [3]]}],
+		"big": 9223372036854775807
+	}`)
+	tests := map[string]struct {
+		args     []interface{}
+		expected interface{}
+	}{
+		"string":             {args: []interface{}{"user.name"}, expected: "ana"},
+		"integer":            {args: []interface{}{"user.age"}, expected: 31},
+		"float":              {args: []interface{}{"user.score"}, expected: 4.5},
+		"bool":               {args: []interface{}{"user.active"}, expected: true},
+		"array index":        {args: []interface{}{"orders[0].amount"}, expected: 120},
+		"nested array index": {args: []interface{}{"orders[1].items[0][1]"}, expected: 2},
+		"cast int to float":  {args: []interface{}{"orders[0].amount", "float64"}, expected: float64(120)},
+		"cast int to int64":  {args: []interface{}{"user.age", "int64"}, expected: int64(31)},
+		"int64":              {args: []interface{}{"big", "int64"}, expected: int64(9223372036854775807)},
+	}
+	for name, tc := range tests {
+		t.Run(name, func(t *testing.T) {
+			got, err := GetMapValue(doc, tc.args...)
+			require.NoError(t, err)
+			assert.Equal(t, tc.expected, got)
+		})
+	}
+	t.Run("index over a root array", func(t *testing.T) {
+		got, err := GetMapValue([]interface{}{"a", "b"}, "[1]")
+		require.NoError(t, err)
+		assert.Equal(t, "b", got)
+	})
+	t.Run("float64 values decoded without UseNumber", func(t *testing.T) {
+		got, err := GetMapValue(map[string]interface{}{"n": float64(3)}, "n", "int")
+		require.NoError(t, err)
+		assert.Equal(t, 3, got)
+	})
+	testErrors := map[string]struct {
+		args []interface{}
+		err  error
+		msg  string
+	}{
+		"missing key":        {args: []interface{}{"user.email"}, err: ErrMapKeyNotFound, msg: "getMapValue key not found: user.email"},
+		"index out of range": {args: []interface{}{"orders[2].amount"}, err: ErrMapKeyNotFound, msg: "getMapValue key not found: orders[2]"},
+		"index over object":  {args: []interface{}{"user[0]"}, err: ErrMapKeyNotFound, msg: "getMapValue key not found: user is not an array"},
+		"key over scalar":    {args: []interface{}{"user.name.first"}, err: ErrMapKeyNotFound, msg: "getMapValue key not found: user.name is not an object or array"},
+		"invalid path":       {args: []interface{}{"orders[a]"}, err: ErrInvalidPath},
+		"empty path":         {args: []interface{}{""}, err: ErrInvalidPath},
+		"no args":            {args: []interface{}{}, err: ErrInvalidMapArgs},
+		"path not string":    {args: []interface{}{1}, err: ErrInvalidMapArgs},
+		"too many args":      {args: []interface{}{"user.age", "int", 1}, err: ErrInvalidMapArgs},
+		"cast not a number":  {args: []interface{}{"user.name", "int"}, err: ErrInvalidCast},
+		"cast float to int":  {args: []interface{}{"user.score", "int"}, err: ErrInvalidCast},
+		"cast unknown type":  {args: []interface{}{"user.age", "string"}, err: ErrInvalidCast},
+	}
+	for name, tc := range testErrors {
+		t.Run(name, func(t *testing.T) {
+			_, err := GetMapValue(doc, tc.args...)
+			require.Error(t, err)
+			assert.True(t, errors.Is(err, tc.err), err.Error())
+			if tc.msg != "" {
+				assert.EqualError(t, err, tc.msg)
+			}
+		})
+	}
+	t.Run("nil input", func(t *testing.T) {
+		_, err := GetMapValue(nil, "user")
+		assert.Equal(t, ErrInvalidMapArgs, err)
+	})
+}
+
+func TestNormalizeJSON(t *testing.T) {
+	t.Parallel()
+	doc := decodeJSON(t, `{"a": 1, "b": [2.5, {"c": 12345678901234567890}], "d": "3"}`)
+	expected := map[string]interface{}{
+		"a": 1,
+		"b": []interface{}{2.5, map[string]interface{}{"c": 12345678901234567890.0}},
+		"d": "3",
+	}
+	assert.Equal(t, expected, NormalizeJSON(doc))
+	assert.Equal(t, decodeJSON(t, `{"a": 1, "b": [2.5, {"c": 12345678901234567890}], "d": "3"}`), doc)
+}
+
+func TestParsePath(t *testing.T) {
+	t.Parallel()
+	tests := map[string]struct {
+		path     string
+		expected []pathSegment
+	}{
+		"single key":     {"a", []pathSegment{{key: "a"}}},
+		"dotted keys":    {"a.b", []pathSegment{{key: "a"}, {key: "b"}}},
+		"indices":        {"a[1][2].b", []pathSegment{{key: "a"}, {index: 1, isIndex: true}, {index: 2, isIndex: true}, {key: "b"}}},
+		"starting index": {"[0].a", []pathSegment{{index: 0, isIndex: true}, {key: "a"}}},
+	}
+	for name, tc := range tests {
+		t.Run(name, func(t *testing.T) {
+			got, err := parsePath(tc.path)
+			require.NoError(t, err)
+			assert.Equal(t, tc.expected, got)
+			assert.Equal(t, tc.path, joinPath(got, len(got)))
+		})
+	}
+	for _, path := range []string{"", ".", "a.", "a..b", "a.[0]", "a[", "a[-1]", "a[1]b", "a]"} {
+		t.Run("invalid "+path, func(t *testing.T) {
+			_, err := parsePath(path)
+			assert.Equal(t, ErrInvalidPath, err)
+		})
+	}
+}
@@ -0,0 +1,194 @@
+package function
+
+import (
+	"math"
+
+	"github.com/sgrodriguez/ddt/compare"
+)
+
+// Abs returns the absolute value of the input number, keeping its type
+func Abs(str interface{}, args ...interface{}) (interface{}, error) {
+	if err := checkArgs("abs", args, 0, 0); err != nil {
+		return nil, err
+	}
+	// the negation of the minimum integer overflows back to itself
+	switch n := str.(type) {
+	case int:
+		if n < 0 && -n < 0 {
+			return nil, overflowError("abs", str)
+		}
+		if n < 0 {
+			return -n, nil
+		}
+		return n, nil
+	case int64:
+		if n < 0 && -n < 0 {
+			return nil, overflowError("abs", str)
+		}
+		if n < 0 {
+			return -n, nil
+		}
+		return n, nil
+	}
+	n, ok := compare.NumberOf(str)
+	if !ok {
+		return nil, inputError("abs", str)
+	}
+	return math.Abs(n.Float64()), nil
+}
+
+// Round rounds the input number half away from zero to the optional number
+// of decimals arg, integers are returned as is
+func Round(str interface{}, args ...interface{}) (interface{}, error) {
+	decimals, err := roundArgs(args)
+	if err != nil {
+		return nil, err
+	}
+	return roundNumber("round", str, func(f float64) float64 {
+		if decimals == 0 {
+			return math.Round(f)
+		}
+		pow := math.Pow10(decimals)
+		return math.Round(f*pow) / pow
+	})
+}
+
+// roundArgs returns the optional decimals arg
+func roundArgs(args []interface{}) (int, error) {
+	if err := checkArgs("round", args, 0, 1); err != nil {
+		return 0, err
+	}
+	if len(args) == 0 {
+		return 0, nil
+	}
+	return intArg("round", args, 0)
+}
+
+// Floor returns the greatest integer value less than or equal to the input
+// number, as a float64 for floats and as is for integers
+func Floor(str interface{}, args ...interface{}) (interface{}, error) {
+	if err := checkArgs("floor", args, 0, 0); err != nil {
+		return nil, err
+	}
+	return roundNumber("floor", str, math.Floor)
+}
+
+// Ceil returns the least integer value greater than or equal to the input
+// number, as a float64 for floats and as is for integers
+func Ceil(str interface{}, args ...interface{}) (interface{}, error) {
+	if err := checkArgs("ceil", args, 0, 0); err != nil {
+		return nil, err
+	}
+	return roundNumber("ceil", str, math.Ceil)
+}
+
+func roundNumber(fn string, str interface{}, round func(float64) float64) (interface{}, error) {
+	switch str.(type) {
+	case int, int64:
+		return str, nil
+	}
+	n, ok := compare.NumberOf(str)
+	if !ok {
+		return nil, inputError(fn, str)
+	}
+	if _, isInt := n.Int64(); isInt {
+		return str, nil
+	}
+	return round(n.Float64()), nil
+}
+
+// Add adds the number arg to the input number
+func Add(str interface{}, args ...interface{}) (interface{}, error) {
+	return arithmetic("add", str, args, addInt64, func(a, b float64) float64 { return a + b })
+}
+
+// addInt64 adds two integers, it is not ok when the sum overflows
+func addInt64(a, b int64) (int64, bool) {
+	c := a + b
+	return c, (c > a) == (b > 0)
+}
+
+// Sub subtracts the number arg from the input number
+func Sub(str interface{}, args ...interface{}) (interface{}, error) {
+	return arithmetic("sub", str, args, func(a, b int64) (int64, bool) {
+		c := a - b
+		return c, (c < a) == (b > 0)
+	}, func(a, b float64) float64 { return a - b })
+}
+
+// Mul multiplies the input number by the number arg
+func Mul(str interface{}, args ...interface{}) (interface{}, error) {
+	return arithmetic("mul", str, args, func(a, b int64) (int64, bool) {
+		if a == 0 || b == 0 {
+			return 0, true
+		}
+		c := a * b
+		return c, c/b == a && !(a == -1 && b == math.MinInt64) && !(b == -1 && a == math.MinInt64)
+	}, func(a, b float64) float64 { return a * b })
+}
+
+// Div divides the input number by the number arg, the result is always a
+// float64
+func Div(str interface{}, args ...interface{}) (interface{}, error) {
+	d, err := divArgs(args)
+	if err != nil {
+		return nil, err
+	}
+	n, ok := compare.NumberOf(str)
+	if !ok {
+		return nil, inputError("div", str)
+	}
+	return n.Float64() / d.Float64(), nil
+}
+
+// divArgs returns the divisor arg, which can not be zero
+func divArgs(args []interface{}) (compare.Number, error) {
+	d, err := arithmeticArgs("div", args)
+	if err != nil {
+		return compare.Number{}, err
+	}
+	if d.Float64() == 0 {
+		return compare.Number{}, argsError("div", "division by zero")
+	}
+	return d, nil
+}
+
+// arithmeticArgs returns the number arg of an arithmetic function
+func arithmeticArgs(fn string, args []interface{}) (compare.Number, error) {
+	if err := checkArgs(fn, args, 1, 1); err != nil {
+		return compare.Number{}, err
+	}
+	return numberArg(fn, args, 0)
+}
+
+// arithmetic applies an operation to the input number and the number arg.
+// When both are int or int64 the result has the type of the input, failing
+// if it overflows, otherwise it is a float64.
+func arithmetic(fn string, str interface{}, args []interface{}, intOp func(a, b int64) (int64, bool), floatOp func(a, b float64) float64) (interface{}, error) {
+	arg, err := arithmeticArgs(fn, args)
+	if err != nil {
+		return nil, err
+	}
+	n, ok := compare.NumberOf(str)
+	if !ok {
+		return nil, inputError(fn, str)
+	}
+	switch str.(type) {
+	case int, int64:
+		if b, isInt := arg.Int64(); isInt {
+			a, _ := n.Int64()
+			res, ok := intOp(a, b)
+			if !ok {
+				return nil, argsError(fn, "%v and %v overflow", str, args[0])
+			}
+			if _, isInt := str.(int); isInt {
+				if int64(int(res)) != res {
+					return nil, argsError(fn, "%v and %v overflow int", str, args[0])
+				}
+				return int(res), nil
+			}
+			return res, nil
+		}
+	}
+	return floatOp(n.Float64(), arg.Float64()), nil
+}
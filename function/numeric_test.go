@@ -0,0 +1,70 @@
+package function
+
+import (
+	"math"
+	"testing"
+)
+
+func TestAbs(t *testing.T) {
+	t.Parallel()
+	runPreProcessCases(t, Abs, map[string]preProcessCase{
+		"int":        {input: -3, expected: 3},
+		"int64":      {input: int64(-3), expected: int64(3)},
+		"float":      {input: -2.5, expected: 2.5},
+		"positive":   {input: 4, expected: 4},
+		"not number": {input: "-1", err: ErrInvalidInput},
+		"with args":  {input: 1, args: []interface{}{1}, err: ErrInvalidArgs},
+		"min int":    {input: math.MinInt64, err: ErrInvalidInput},
+		"min int64":  {input: int64(math.MinInt64), err: ErrInvalidInput},
+	})
+}
+
+func TestRounding(t *testing.T) {
+	t.Parallel()
+	runPreProcessCases(t, Round, map[string]preProcessCase{
+		"half away from zero": {input: -2.5, expected: -3.0},
+		"decimals":            {input: 3.14159, args: []interface{}{2}, expected: 3.14},
+		"int":                 {input: 7, expected: 7},
+		"invalid decimals":    {input: 1.5, args: []interface{}{"2"}, err: ErrInvalidArgs},
+		"not number":          {input: true, err: ErrInvalidInput},
+	})
+	runPreProcessCases(t, Floor, map[string]preProcessCase{
+		"float":    {input: 2.7, expected: 2.0},
+		"negative": {input: -2.1, expected: -3.0},
+		"int64":    {input: int64(2), expected: int64(2)},
+	})
+	runPreProcessCases(t, Ceil, map[string]preProcessCase{
+		"float": {input: 2.1, expected: 3.0},
+		"int":   {input: 2, expected: 2},
+	})
+}
+
+func TestArithmetic(t *testing.T) {
+	t.Parallel()
+	runPreProcessCases(t, Add, map[string]preProcessCase{
+		"ints":             {input: 1, args: []interface{}{2}, expected: 3},
+		"int64 and int":    {input: int64(1), args: []interface{}{2}, expected: int64(3)},
+		"int and float":    {input: 1, args: []interface{}{0.5}, expected: 1.5},
+		"overflow":         {input: int64(math.MaxInt64), args: []interface{}{1}, err: ErrInvalidArgs},
+		"missing arg":      {input: 1, err: ErrInvalidArgs},
+		"arg not number":   {input: 1, args: []interface{}{"1"}, err: ErrInvalidArgs},
+		"input not number": {input: "1", args: []interface{}{1}, err: ErrInvalidInput},
+	})
+	runPreProcessCases(t, Sub, map[string]preProcessCase{
+		"ints":     {input: 1, args: []interface{}{2}, expected: -1},
+		"floats":   {input: 1.5, args: []interface{}{0.5}, expected: 1.0},
+		"overflow": {input: int64(math.MinInt64), args: []interface{}{1}, err: ErrInvalidArgs},
+	})
+	runPreProcessCases(t, Mul, map[string]preProcessCase{
+		"ints":           {input: 3, args: []interface{}{-2}, expected: -6},
+		"zero":           {input: int64(math.MaxInt64), args: []interface{}{0}, expected: int64(0)},
+		"float":          {input: 3, args: []interface{}{0.5}, expected: 1.5},
+		"overflow":       {input: int64(math.MaxInt64), args: []interface{}{2}, err: ErrInvalidArgs},
+		"min by minus 1": {input: int64(math.MinInt64), args: []interface{}{-1}, err: ErrInvalidArgs},
+	})
+	runPreProcessCases(t, Div, map[string]preProcessCase{
+		"ints":       {input: 3, args: []interface{}{2}, expected: 1.5},
+		"by zero":    {input: 3, args: []interface{}{0.0}, err: ErrInvalidArgs},
+		"not number": {input: nil, args: []interface{}{1}, err: ErrInvalidInput},
+	})
+}
@@ -0,0 +1,74 @@
+package function
+
+import (
+	"errors"
+	"strconv"
+	"strings"
+)
+
+// ErrInvalidPath is returned for malformed attribute or key paths
+var ErrInvalidPath = errors.New("invalid path")
+
+// pathSegment is a key or an index of a path like "orders[0].amount"
+type pathSegment struct {
+	key     string
+	index   int
+	isIndex bool
+}
+
+func (s pathSegment) String() string {
+	if s.isIndex {
+		return "[" + strconv.Itoa(s.index) + "]"
+	}
+	return s.key
+}
+
+// parsePath splits a path in keys separated by dots, each key can be
+// followed by indices between brackets (ie "orders[0].items[1][2]")
+func parsePath(path string) ([]pathSegment, error) {
+	if path == "" {
+		return nil, ErrInvalidPath
+	}
+	var segments []pathSegment
+	for i, part := range strings.Split(path, ".") {
+		key := part
+		if idx := strings.IndexByte(part, '['); idx >= 0 {
+			key = part[:idx]
+			part = part[idx:]
+		} else {
+			part = ""
+		}
+		// only the first part can start with an index, ie "[0].name"
+		if (key == "" && (i != 0 || part == "")) || strings.IndexByte(key, ']') >= 0 {
+			return nil, ErrInvalidPath
+		}
+		if key != "" {
+			segments = append(segments, pathSegment{key: key})
+		}
+		for part != "" {
+			end := strings.IndexByte(part, ']')
+			if part[0] != '[' || end < 0 {
+				return nil, ErrInvalidPath
+			}
+			index, err := strconv.Atoi(part[1:end])
+			if err != nil || index < 0 {
+				return nil, ErrInvalidPath
+			}
+			segments = append(segments, pathSegment{index: index, isIndex: true})
+			part = part[end+1:]
+		}
+	}
+	return segments, nil
+}
+
+// joinPath returns the path of the first n segments, used in error messages
+func joinPath(segments []pathSegment, n int) string {
+	var sb strings.Builder
+	for i, s := range segments[:n] {
+		if i != 0 && !s.isIndex {
+			sb.WriteByte('.')
+		}
+		sb.WriteString(s.String())
+	}
+	return sb.String()
+}
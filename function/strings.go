@@ -0,0 +1,156 @@
+package function
+
+import (
+	"fmt"
+	"strings"
+	"unicode/utf8"
+)
+
+// Lower returns the input string in lower case
+func Lower(str interface{}, args ...interface{}) (interface{}, error) {
+	s, err := stringInput("lower", str, args, 0, 0)
+	if err != nil {
+		return nil, err
+	}
+	return strings.ToLower(s), nil
+}
+
+// Upper returns the input string in upper case
+func Upper(str interface{}, args ...interface{}) (interface{}, error) {
+	s, err := stringInput("upper", str, args, 0, 0)
+	if err != nil {
+		return nil, err
+	}
+	return strings.ToUpper(s), nil
+}
+
+// Trim removes the leading and trailing white space of the input string, or
+// the characters of the cutset given as arg
+func Trim(str interface{}, args ...interface{}) (interface{}, error) {
+	s, err := stringInput("trim", str, args, 0, 1)
+	if err != nil {
+		return nil, err
+	}
+	if err := trimArgs(args); err != nil {
+		return nil, err
+	}
+	if len(args) == 0 {
+		return strings.TrimSpace(s), nil
+	}
+	return strings.Trim(s, args[0].(string)), nil
+}
+
+func trimArgs(args []interface{}) error {
+	if err := checkArgs("trim", args, 0, 1); err != nil {
+		return err
+	}
+	if len(args) == 1 {
+		_, err := stringArg("trim", args, 0)
+		return err
+	}
+	return nil
+}
+
+// Substring returns the characters of the input string from the start arg to
+// the optional end arg (exclusive), the positions are counted in runes and
+// clamped to the length of the string
+func Substring(str interface{}, args ...interface{}) (interface{}, error) {
+	s, err := stringInput("substring", str, args, 1, 2)
+	if err != nil {
+		return nil, err
+	}
+	start, end, hasEnd, err := substringArgs(args)
+	if err != nil {
+		return nil, err
+	}
+	runes := []rune(s)
+	if !hasEnd || end > len(runes) {
+		end = len(runes)
+	}
+	if start > end {
+		return "", nil
+	}
+	return string(runes[start:end]), nil
+}
+
+// substringArgs returns the start and the optional end args
+func substringArgs(args []interface{}) (start, end int, hasEnd bool, err error) {
+	if err := checkArgs("substring", args, 1, 2); err != nil {
+		return 0, 0, false, err
+	}
+	if start, err = intArg("substring", args, 0); err != nil {
+		return 0, 0, false, err
+	}
+	if len(args) == 2 {
+		if end, err = intArg("substring", args, 1); err != nil {
+			return 0, 0, false, err
+		}
+		hasEnd = true
+	}
+	if start < 0 || (hasEnd && end < start) {
+		return 0, 0, false, argsError("substring", "invalid range [%d, %d)", start, end)
+	}
+	return start, end, hasEnd, nil
+}
+
+// Length returns the number of characters (runes) of the input string
+func Length(str interface{}, args ...interface{}) (interface{}, error) {
+	s, err := stringInput("length", str, args, 0, 0)
+	if err != nil {
+		return nil, err
+	}
+	return utf8.RuneCountInString(s), nil
+}
+
+// Split splits the input string by the separator arg, returning the parts or
+// only the part at the optional index arg
+func Split(str interface{}, args ...interface{}) (interface{}, error) {
+	s, err := stringInput("split", str, args, 1, 2)
+	if err != nil {
+		return nil, err
+	}
+	sep, index, hasIndex, err := splitArgs(args)
+	if err != nil {
+		return nil, err
+	}
+	parts := strings.Split(s, sep)
+	if !hasIndex {
+		return parts, nil
+	}
+	if index >= len(parts) {
+		// the number of parts depends on the input, not on the args
+		return nil, fmt.Errorf("split %w: %q has %d parts, index %d out of range", ErrInvalidInput, s, len(parts), index)
+	}
+	return parts[index], nil
+}
+
+// splitArgs returns the separator and the optional index args
+func splitArgs(args []interface{}) (sep string, index int, hasIndex bool, err error) {
+	if err := checkArgs("split", args, 1, 2); err != nil {
+		return "", 0, false, err
+	}
+	if sep, err = stringArg("split", args, 0); err != nil {
+		return "", 0, false, err
+	}
+	if len(args) == 2 {
+		if index, err = intArg("split", args, 1); err != nil {
+			return "", 0, false, err
+		}
+		if index < 0 {
+			return "", 0, false, argsError("split", "negative index %d", index)
+		}
+		hasIndex = true
+	}
+	return sep, index, hasIndex, nil
+}
+
+func stringInput(fn string, str interface{}, args []interface{}, min, max int) (string, error) {
+	if err := checkArgs(fn, args, min, max); err != nil {
+		return "", err
+	}
+	s, ok := str.(string)
+	if !ok {
+		return "", inputError(fn, str)
+	}
+	return s, nil
+}
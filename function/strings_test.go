@@ -0,0 +1,90 @@
+package function
+
+import (
+	"errors"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+type preProcessCase struct {
+	input    interface{}
+	args     []interface{}
+	expected interface{}
+	err      error
+}
+
+func runPreProcessCases(t *testing.T, fn func(interface{}, ...interface{}) (interface{}, error), tests map[string]preProcessCase) {
+	for name, tc := range tests {
+		t.Run(name, func(t *testing.T) {
+			got, err := fn(tc.input, tc.args...)
+			if tc.err != nil {
+				require.Error(t, err)
+				assert.True(t, errors.Is(err, tc.err), "unexpected error %v", err)
+				return
+			}
+			require.NoError(t, err)
+			assert.Equal(t, tc.expected, got)
+		})
+	}
+}
+
+func TestLowerUpper(t *testing.T) {
+	t.Parallel()
+	runPreProcessCases(t, Lower, map[string]preProcessCase{
+		"lower":      {input: "HeLLo Ñ", expected: "hello ñ"},
+		"not string": {input: 1, err: ErrInvalidInput},
+		"with args":  {input: "a", args: []interface{}{"b"}, err: ErrInvalidArgs},
+	})
+	runPreProcessCases(t, Upper, map[string]preProcessCase{
+		"upper":      {input: "HeLLo ñ", expected: "HELLO Ñ"},
+		"not string": {input: nil, err: ErrInvalidInput},
+	})
+}
+
+func TestTrim(t *testing.T) {
+	t.Parallel()
+	runPreProcessCases(t, Trim, map[string]preProcessCase{
+		"spaces":          {input: " \thi \n", expected: "hi"},
+		"cutset":          {input: "--hi-", args: []interface{}{"-"}, expected: "hi"},
+		"cutset not text": {input: "hi", args: []interface{}{1}, err: ErrInvalidArgs},
+	})
+}
+
+func TestSubstring(t *testing.T) {
+	t.Parallel()
+	runPreProcessCases(t, Substring, map[string]preProcessCase{
+		"from start":       {input: "santiago", args: []interface{}{4}, expected: "iago"},
+		"range":            {input: "santiago", args: []interface{}{0, 3}, expected: "san"},
+		"runes":            {input: "ñandú", args: []interface{}{1, 5}, expected: "andú"},
+		"end clamped":      {input: "abc", args: []interface{}{1, 10}, expected: "bc"},
+		"start too large":  {input: "abc", args: []interface{}{5}, expected: ""},
+		"int64 args":       {input: "abc", args: []interface{}{int64(1), int64(2)}, expected: "b"},
+		"negative start":   {input: "abc", args: []interface{}{-1}, err: ErrInvalidArgs},
+		"end before start": {input: "abc", args: []interface{}{2, 1}, err: ErrInvalidArgs},
+		"missing start":    {input: "abc", err: ErrInvalidArgs},
+		"float start":      {input: "abc", args: []interface{}{1.5}, err: ErrInvalidArgs},
+	})
+}
+
+func TestLength(t *testing.T) {
+	t.Parallel()
+	runPreProcessCases(t, Length, map[string]preProcessCase{
+		"ascii":      {input: "abc", expected: 3},
+		"runes":      {input: "ñandú", expected: 5},
+		"not string": {input: []string{"a"}, err: ErrInvalidInput},
+	})
+}
+
+func TestSplit(t *testing.T) {
+	t.Parallel()
+	runPreProcessCases(t, Split, map[string]preProcessCase{
+		"parts":          {input: "a,b,c", args: []interface{}{","}, expected: []string{"a", "b", "c"}},
+		"part":           {input: "ana@example.com", args: []interface{}{"@", 1}, expected: "example.com"},
+		"out of range":   {input: "a,b", args: []interface{}{",", 2}, err: ErrInvalidInput},
+		"negative index": {input: "a,b", args: []interface{}{",", -1}, err: ErrInvalidArgs},
+		"no separator":   {input: "a,b", err: ErrInvalidArgs},
+		"separator type": {input: "a,b", args: []interface{}{true}, err: ErrInvalidArgs},
+	})
+}
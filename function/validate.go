@@ -0,0 +1,94 @@
+package function
+
+import (
+	"reflect"
+)
+
+// argsCheck validates the args of a function, without its input
+type argsCheck func(args []interface{}) error
+
+func countArgs(fn string, min, max int) argsCheck {
+	return func(args []interface{}) error {
+		return checkArgs(fn, args, min, max)
+	}
+}
+
+// argsChecks are the args checks of the standard library by function
+var argsChecks = map[uintptr]argsCheck{
+	// strings
+	funcPointer(Lower):  countArgs("lower", 0, 0),
+	funcPointer(Upper):  countArgs("upper", 0, 0),
+	funcPointer(Trim):   trimArgs,
+	funcPointer(Length): countArgs("length", 0, 0),
+	funcPointer(Substring): func(args []interface{}) error {
+		_, _, _, err := substringArgs(args)
+		return err
+	},
+	funcPointer(Split): func(args []interface{}) error {
+		_, _, _, err := splitArgs(args)
+		return err
+	},
+	// numbers
+	funcPointer(Abs):   countArgs("abs", 0, 0),
+	funcPointer(Floor): countArgs("floor", 0, 0),
+	funcPointer(Ceil):  countArgs("ceil", 0, 0),
+	funcPointer(Round): func(args []interface{}) error {
+		_, err := roundArgs(args)
+		return err
+	},
+	funcPointer(Add): numberArgs("add"),
+	funcPointer(Sub): numberArgs("sub"),
+	funcPointer(Mul): numberArgs("mul"),
+	funcPointer(Div): func(args []interface{}) error {
+		_, err := divArgs(args)
+		return err
+	},
+	// dates
+	funcPointer(Age):       countArgs("age", 0, 0),
+	funcPointer(DayOfWeek): locationCheck("dayOfWeek"),
+	funcPointer(Hour):      locationCheck("hour"),
+	// collections
+	funcPointer(Len):      countArgs("len", 0, 0),
+	funcPointer(Contains): countArgs("contains", 1, 1),
+	funcPointer(Sum):      countArgs("sum", 0, 0),
+	funcPointer(Min):      countArgs("min", 0, 0),
+	funcPointer(Max):      countArgs("max", 0, 0),
+	// casts
+	funcPointer(ToInt):     countArgs("toInt", 0, 0),
+	funcPointer(ToInt64):   countArgs("toInt64", 0, 0),
+	funcPointer(ToFloat64): countArgs("toFloat64", 0, 0),
+	funcPointer(ToString):  countArgs("toString", 0, 0),
+	funcPointer(ToBool):    countArgs("toBool", 0, 0),
+}
+
+func numberArgs(fn string) argsCheck {
+	return func(args []interface{}) error {
+		_, err := arithmeticArgs(fn, args)
+		return err
+	}
+}
+
+func locationCheck(fn string) argsCheck {
+	return func(args []interface{}) error {
+		_, err := locationArgs(fn, args)
+		return err
+	}
+}
+
+func funcPointer(fn func(str interface{}, args ...interface{}) (interface{}, error)) uintptr {
+	return reflect.ValueOf(fn).Pointer()
+}
+
+// ValidateArgs checks the args of a function of the standard library before
+// calling it, so trees with invalid args are rejected when they are loaded
+// instead of failing on every resolution. The args of other functions are not
+// checked.
+func ValidateArgs(fn PreProcessFn, args ...interface{}) error {
+	if fn.Function == nil {
+		return nil
+	}
+	if check, ok := argsChecks[funcPointer(fn.Function)]; ok {
+		return check(args)
+	}
+	return nil
+}
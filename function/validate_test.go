@@ -0,0 +1,51 @@
+package function
+
+import (
+	"errors"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestValidateArgs(t *testing.T) {
+	t.Parallel()
+	custom := func(str interface{}, args ...interface{}) (interface{}, error) { return str, nil }
+	tests := map[string]struct {
+		fn    PreProcessFn
+		args  []interface{}
+		valid bool
+	}{
+		"no args":              {fn: PreProcessFn{Function: Lower}, valid: true},
+		"unexpected args":      {fn: PreProcessFn{Function: Lower}, args: []interface{}{1}},
+		"trim cutset":          {fn: PreProcessFn{Function: Trim}, args: []interface{}{"-"}, valid: true},
+		"trim invalid cutset":  {fn: PreProcessFn{Function: Trim}, args: []interface{}{1}},
+		"substring":            {fn: PreProcessFn{Function: Substring}, args: []interface{}{1, 3}, valid: true},
+		"substring missing":    {fn: PreProcessFn{Function: Substring}},
+		"substring not int":    {fn: PreProcessFn{Function: Substring}, args: []interface{}{"a"}},
+		"substring range":      {fn: PreProcessFn{Function: Substring}, args: []interface{}{3, 1}},
+		"split index":          {fn: PreProcessFn{Function: Split}, args: []interface{}{",", 2}, valid: true},
+		"split negative index": {fn: PreProcessFn{Function: Split}, args: []interface{}{",", -1}},
+		"round":                {fn: PreProcessFn{Function: Round}, args: []interface{}{2}, valid: true},
+		"round float":          {fn: PreProcessFn{Function: Round}, args: []interface{}{2.5}},
+		"add":                  {fn: PreProcessFn{Function: Add}, args: []interface{}{2.5}, valid: true},
+		"add string":           {fn: PreProcessFn{Function: Add}, args: []interface{}{"1"}},
+		"div by zero":          {fn: PreProcessFn{Function: Div}, args: []interface{}{0}},
+		"location":             {fn: PreProcessFn{Function: Hour}, args: []interface{}{"UTC"}, valid: true},
+		"unknown location":     {fn: PreProcessFn{Function: DayOfWeek}, args: []interface{}{"Nowhere/City"}},
+		"contains":             {fn: PreProcessFn{Function: Contains}, args: []interface{}{1}, valid: true},
+		"contains missing":     {fn: PreProcessFn{Function: Contains}},
+		"cast":                 {fn: PreProcessFn{Function: ToInt}, args: []interface{}{1}},
+		"custom function":      {fn: PreProcessFn{Function: custom}, args: []interface{}{1, "a"}, valid: true},
+		"context function":     {fn: PreProcessFn{}, args: []interface{}{1}, valid: true},
+	}
+	for name, tc := range tests {
+		t.Run(name, func(t *testing.T) {
+			err := ValidateArgs(tc.fn, tc.args...)
+			if tc.valid {
+				assert.NoError(t, err)
+				return
+			}
+			assert.True(t, errors.Is(err, ErrInvalidArgs), "%v", err)
+		})
+	}
+}
@@ -0,0 +1,227 @@
+package ddt
+
+import (
+	"bufio"
+	"fmt"
+	"io"
+	"strings"
+
+	"github.com/sgrodriguez/ddt/compare"
+	"github.com/sgrodriguez/ddt/value"
+)
+
+// WriteDOT writes the tree as a Graphviz DOT digraph. Nodes are labelled with
+// their pre process function and args, leaves with their result and edges
+// with the comparer and value to compare. The nodes in highlight (ie the
+// Trace.VisitedIDs of a resolution) and the edges between them are highlighted.
+// A tree without root fails with ErrMissingRoot.
+func WriteDOT(w io.Writer, t *Tree, highlight ...int) error {
+	if t.Root == nil {
+		return ErrMissingRoot
+	}
+	bw := bufio.NewWriter(w)
+	path := highlightSet(highlight)
+	fmt.Fprintf(bw, "digraph %s {\n", dotQuote(t.Name))
+	walkEdges(t.Root, func(n *Node) {
+		attrs := "shape=ellipse"
+		if len(n.Children) == 0 {
+			attrs = "shape=box"
+		}
+		if path[n.ID] {
+			attrs += ", color=red, penwidth=2"
+		}
+		fmt.Fprintf(bw, "  n%d [label=%s, %s];\n", n.ID, dotQuote(nodeLabel(n)), attrs)
+	}, func(parent, child *Node) {
+		attrs := ""
+		if path[parent.ID] && path[child.ID] {
+			attrs = ", color=red, penwidth=2"
+		}
+		fmt.Fprintf(bw, "  n%d -> n%d [label=%s%s];\n", parent.ID, child.ID, dotQuote(edgeLabel(child)), attrs)
+	})
+	fmt.Fprintln(bw, "}")
+	return bw.Flush()
+}
+
+// WriteMermaid writes the tree as a Mermaid flowchart, labelled and
+// highlighted like WriteDOT
+func WriteMermaid(w io.Writer, t *Tree, highlight ...int) error {
+	if t.Root == nil {
+		return ErrMissingRoot
+	}
+	bw := bufio.NewWriter(w)
+	path := highlightSet(highlight)
+	var styles []string
+	edge := 0
+	fmt.Fprintln(bw, "flowchart TD")
+	walkEdges(t.Root, func(n *Node) {
+		if len(n.Children) == 0 {
+			fmt.Fprintf(bw, "  n%d(%s)\n", n.ID, mermaidQuote(nodeLabel(n)))
+		} else {
+			fmt.Fprintf(bw, "  n%d[%s]\n", n.ID, mermaidQuote(nodeLabel(n)))
+		}
+		if path[n.ID] {
+			styles = append(styles, fmt.Sprintf("  style n%d stroke:#f00,stroke-width:3px", n.ID))
+		}
+	}, func(parent, child *Node) {
+		fmt.Fprintf(bw, "  n%d -->|%s| n%d\n", parent.ID, mermaidQuote(edgeLabel(child)), child.ID)
+		if path[parent.ID] && path[child.ID] {
+			styles = append(styles, fmt.Sprintf("  linkStyle %d stroke:#f00,stroke-width:3px", edge))
+		}
+		edge++
+	})
+	for _, s := range styles {
+		fmt.Fprintln(bw, s)
+	}
+	return bw.Flush()
+}
+
+// walkEdges visits the nodes in breadth first order, calling node for each
+// node and edge for each parent child relation after visiting the parent
+func walkEdges(root *Node, node func(n *Node), edge func(parent, child *Node)) {
+	nodes := getAllNodes(root)
+	for _, n := range nodes {
+		node(n)
+	}
+	for _, n := range nodes {
+		for _, c := range n.Children {
+			edge(n, c)
+		}
+	}
+}
+
+func highlightSet(ids []int) map[int]bool {
+	res := map[int]bool{}
+	for _, id := range ids {
+		res[id] = true
+	}
+	return res
+}
+
+// nodeLabel is the id of the node followed by its pre process function call
+// or expression, or its result when it is a leaf
+func nodeLabel(n *Node) string {
+	label := fmt.Sprintf("#%d", n.ID)
+	if len(n.Children) == 0 {
+		if n.Result == nil {
+			return label
+		}
+		return label + " " + formatValue(n.Result)
+	}
+	if n.Expression != "" {
+		return label + " " + n.Expression
+	}
+	if len(n.Pipeline) != 0 {
+		calls := make([]string, len(n.Pipeline))
+		for i, s := range n.Pipeline {
+			if s != nil {
+				calls[i] = callLabel(s.PreProcessFn.Name, s.PreProcessArgs)
+			}
+		}
+		return label + " " + strings.Join(calls, " | ")
+	}
+	if n.PreProcessFn.Empty() {
+		return label + " input"
+	}
+	return label + " " + callLabel(n.PreProcessFn.Name, n.PreProcessArgs)
+}
+
+// callLabel is a pre process function call with its args (ie Fn("a", 1))
+func callLabel(name string, args []*value.Value) string {
+	formatted := make([]string, len(args))
+	for i, a := range args {
+		formatted[i] = formatValue(a)
+	}
+	return fmt.Sprintf("%s(%s)", name, strings.Join(formatted, ", "))
+}
+
+// edgeLabel is the comparer of the child with the value to compare (ie >= 30)
+func edgeLabel(child *Node) string {
+	if child.Default {
+		return "else"
+	}
+	return comparerLabel(child.Comparer, child.ValueToCompare)
+}
+
+func comparerLabel(c Comparer, v *value.Value) string {
+	operand := formatValue(v)
+	switch comp := c.(type) {
+	case *compare.Equal:
+		return "== " + operand
+	case *compare.Greater:
+		if comp.Equal {
+			return ">= " + operand
+		}
+		return "> " + operand
+	case *compare.Lesser:
+		if comp.Equal {
+			return "<= " + operand
+		}
+		return "< " + operand
+	case *compare.In:
+		return "in " + operand
+	case *compare.NotIn:
+		return "not in " + operand
+	case *compare.And:
+		return operandsLabel(comp.Operands, " and ")
+	case *compare.Or:
+		return operandsLabel(comp.Operands, " or ")
+	case *compare.Not:
+		if comp.Operand != nil {
+			return "not (" + comparerLabel(comp.Operand.Comparer, comp.Operand.Value) + ")"
+		}
+	case *compare.Between:
+		if bounds, ok := interfaceValue(v).([]interface{}); ok && len(bounds) == 2 {
+			lower, upper := "(", ")"
+			if comp.LowerInclusive {
+				lower = "["
+			}
+			if comp.UpperInclusive {
+				upper = "]"
+			}
+			return fmt.Sprintf("in %s%s, %s%s", lower, formatInterface(bounds[0]), formatInterface(bounds[1]), upper)
+		}
+	}
+	// comparers are labelled with their json type
+	return comparerType(c) + " " + operand
+}
+
+// operandsLabel joins the labels of the operands of a composite comparer
+func operandsLabel(operands []*compare.Operand, sep string) string {
+	labels := make([]string, len(operands))
+	for i, op := range operands {
+		if op != nil {
+			labels[i] = comparerLabel(op.Comparer, op.Value)
+		}
+	}
+	return "(" + strings.Join(labels, sep) + ")"
+}
+
+func formatValue(v *value.Value) string {
+	if v == nil {
+		return ""
+	}
+	return formatInterface(v.Value)
+}
+
+func formatInterface(v interface{}) string {
+	switch val := v.(type) {
+	case string:
+		return fmt.Sprintf("%q", val)
+	case []interface{}:
+		elems := make([]string, len(val))
+		for i, e := range val {
+			elems[i] = formatInterface(e)
+		}
+		return "[" + strings.Join(elems, ", ") + "]"
+	}
+	return fmt.Sprintf("%v", v)
+}
+
+func dotQuote(s string) string {
+	s = strings.ReplaceAll(s, `\`, `\\`)
+	return `"` + strings.ReplaceAll(s, `"`, `\"`) + `"`
+}
+
+func mermaidQuote(s string) string {
+	return `"` + strings.ReplaceAll(s, `"`, "#quot;") + `"`
+}
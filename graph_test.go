@@ -0,0 +1,70 @@
+package ddt
+
+import (
+	"bytes"
+	"errors"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestWriteDOT(t *testing.T) {
+	ut := userTree()
+	_, trace, err := ExplainTree(ut, newUser(25, "LUCIA", "SANTIAGO"))
+	require.NoError(t, err)
+	var buf bytes.Buffer
+	require.NoError(t, WriteDOT(&buf, ut, trace.VisitedIDs()...))
+	expected := `digraph "userTree" {
+  n0 [label="#0 CallStructMethod(\"UnderAge\")", shape=ellipse, color=red, penwidth=2];
+  n1 [label="#1 CallStructMethod(\"FullName\")", shape=ellipse];
+  n2 [label="#2 GetStructAttribute(\"Age\")", shape=ellipse, color=red, penwidth=2];
+  n3 [label="#3 \"node3\"", shape=box];
+  n4 [label="#4 \"node4\"", shape=box];
+  n5 [label="#5 \"node5\"", shape=box, color=red, penwidth=2];
+  n6 [label="#6 \"node6\"", shape=box];
+  n0 -> n1 [label="== true"];
+  n0 -> n2 [label="== false", color=red, penwidth=2];
+  n1 -> n3 [label="== \"SANTIAGO LUCIA\""];
+  n1 -> n4 [label="== \"LUCIA SANTIAGO\""];
+  n2 -> n5 [label="<= 30", color=red, penwidth=2];
+  n2 -> n6 [label="> 30"];
+}
+`
+	assert.Equal(t, expected, buf.String())
+}
+
+func TestWriteMermaid(t *testing.T) {
+	ut := userTree()
+	ut.Root.Children[1].Children[1].Default = true
+	var buf bytes.Buffer
+	require.NoError(t, WriteMermaid(&buf, ut, 0, 2, 6))
+	expected := `flowchart TD
+  n0["#0 CallStructMethod(#quot;UnderAge#quot;)"]
+  n1["#1 CallStructMethod(#quot;FullName#quot;)"]
+  n2["#2 GetStructAttribute(#quot;Age#quot;)"]
+  n3("#3 #quot;node3#quot;")
+  n4("#4 #quot;node4#quot;")
+  n5("#5 #quot;node5#quot;")
+  n6("#6 #quot;node6#quot;")
+  n0 -->|"== true"| n1
+  n0 -->|"== false"| n2
+  n1 -->|"== #quot;SANTIAGO LUCIA#quot;"| n3
+  n1 -->|"== #quot;LUCIA SANTIAGO#quot;"| n4
+  n2 -->|"<= 30"| n5
+  n2 -->|"else"| n6
+  style n0 stroke:#f00,stroke-width:3px
+  style n2 stroke:#f00,stroke-width:3px
+  style n6 stroke:#f00,stroke-width:3px
+  linkStyle 1 stroke:#f00,stroke-width:3px
+  linkStyle 5 stroke:#f00,stroke-width:3px
+`
+	assert.Equal(t, expected, buf.String())
+}
+
+func TestWriteGraph_MissingRoot(t *testing.T) {
+	var buf bytes.Buffer
+	assert.True(t, errors.Is(WriteDOT(&buf, &Tree{Name: "empty"}), ErrMissingRoot))
+	assert.True(t, errors.Is(WriteMermaid(&buf, &Tree{Name: "empty"}), ErrMissingRoot))
+	assert.Empty(t, buf.String())
+}
@@ -0,0 +1,123 @@
+package ddt
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"sync/atomic"
+
+	"gopkg.in/yaml.v3"
+)
+
+// Holder serves the resolutions of a tree while new versions of it are
+// loaded. A Tree is not safe for concurrent use when it is modified (ie
+// decoding json into a tree being resolved), the Holder instead validates and
+// compiles every new version before swapping it atomically, so resolutions
+// are lock free and always see a complete version of the tree. The trees
+// given to or returned by a Holder must not be modified. The zero value holds
+// no tree, resolving with it fails with ErrEmptyHolder until a tree is stored.
+type Holder struct {
+	current atomic.Value
+}
+
+// ErrEmptyHolder is returned resolving with a Holder without a tree
+var ErrEmptyHolder = errors.New("holder without tree")
+
+// treeVersion is the value swapped by the Holder
+type treeVersion struct {
+	tree      *Tree
+	evaluator *Evaluator
+}
+
+// NewHolder creates a holder serving the given tree
+func NewHolder(t *Tree) (*Holder, error) {
+	h := &Holder{}
+	if err := h.Store(t); err != nil {
+		return nil, err
+	}
+	return h, nil
+}
+
+// Tree returns the current version of the tree, it must not be modified. It
+// is nil when no tree was stored.
+func (h *Holder) Tree() *Tree {
+	v := h.version()
+	if v == nil {
+		return nil
+	}
+	return v.tree
+}
+
+// Store validates and compiles the tree, replacing the current version when valid
+func (h *Holder) Store(t *Tree) error {
+	evaluator, err := Compile(t)
+	if err != nil {
+		return err
+	}
+	h.current.Store(&treeVersion{tree: t, evaluator: evaluator})
+	return nil
+}
+
+// LoadJSON decodes a new version of the tree with the functions and comparers
+// of the current version, the current version is kept when the decoding fails.
+func (h *Holder) LoadJSON(data []byte) error {
+	tree := h.newVersion()
+	if err := json.Unmarshal(data, tree); err != nil {
+		return err
+	}
+	return h.Store(tree)
+}
+
+// LoadYAML decodes a new version of the tree from yaml like LoadJSON
+func (h *Holder) LoadYAML(data []byte) error {
+	tree := h.newVersion()
+	if err := yaml.Unmarshal(data, tree); err != nil {
+		return err
+	}
+	return h.Store(tree)
+}
+
+// newVersion returns an empty tree with the functions and comparers of the
+// current version
+func (h *Holder) newVersion() *Tree {
+	current := h.Tree()
+	if current == nil {
+		return &Tree{}
+	}
+	return &Tree{Functions: current.Functions, Comparers: current.Comparers}
+}
+
+// Resolve resolves the current version of the tree given a input
+func (h *Holder) Resolve(input interface{}) (interface{}, error) {
+	return h.ResolveContext(context.Background(), input)
+}
+
+// ResolveContext resolves the current version of the tree like ResolveTreeContext
+func (h *Holder) ResolveContext(ctx context.Context, input interface{}) (interface{}, error) {
+	v := h.version()
+	if v == nil {
+		return nil, ErrEmptyHolder
+	}
+	return v.evaluator.ResolveContext(ctx, input)
+}
+
+// Explain explains the resolution of the current version of the tree like
+// ExplainTree, using the same compiled version as Resolve
+func (h *Holder) Explain(input interface{}) (interface{}, *Trace, error) {
+	return h.ExplainContext(context.Background(), input)
+}
+
+// ExplainContext explains the resolution of the current version of the tree like ExplainTreeContext
+func (h *Holder) ExplainContext(ctx context.Context, input interface{}) (interface{}, *Trace, error) {
+	v := h.version()
+	if v == nil {
+		return nil, &Trace{}, ErrEmptyHolder
+	}
+	return v.evaluator.ExplainContext(ctx, input)
+}
+
+// version returns the current version, nil when no tree was stored
+func (h *Holder) version() *treeVersion {
+	v, _ := h.current.Load().(*treeVersion)
+	return v
+}
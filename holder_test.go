@@ -0,0 +1,130 @@
+package ddt
+
+import (
+	"encoding/json"
+	"errors"
+	"sync"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestHolder(t *testing.T) {
+	holder, err := NewHolder(userTree())
+	require.NoError(t, err)
+	result, err := holder.Resolve(newUser(11, "LUCIA", "SANTIAGO"))
+	require.NoError(t, err)
+	assert.Equal(t, "node4", result)
+	_, trace, err := holder.Explain(newUser(11, "LUCIA", "SANTIAGO"))
+	require.NoError(t, err)
+	assert.Equal(t, []int{0, 1, 4}, trace.VisitedIDs())
+
+	t.Run("invalid version is not stored", func(t *testing.T) {
+		err := holder.LoadJSON([]byte(`{"name":"invalid","nodes":[{"id":1,"parentId":0}]}`))
+		assert.True(t, errors.Is(err, ErrMissingRoot))
+		err = holder.Store(&Tree{Name: "invalid"})
+		var validationErr *ValidationError
+		assert.True(t, errors.As(err, &validationErr))
+		assert.Equal(t, "userTree", holder.Tree().Name)
+	})
+	t.Run("load new version", func(t *testing.T) {
+		b, err := json.Marshal(holder.Tree())
+		require.NoError(t, err)
+		modified := modifyNode4Result(t, b, "modified")
+		require.NoError(t, holder.LoadJSON(modified))
+		result, err := holder.Resolve(newUser(11, "LUCIA", "SANTIAGO"))
+		require.NoError(t, err)
+		assert.Equal(t, "modified", result)
+	})
+	_, err = NewHolder(&Tree{})
+	assert.Error(t, err)
+}
+
+func TestHolder_ZeroValue(t *testing.T) {
+	var holder Holder
+	assert.Nil(t, holder.Tree())
+	_, err := holder.Resolve(newUser(11, "LUCIA", "SANTIAGO"))
+	assert.True(t, errors.Is(err, ErrEmptyHolder))
+	_, trace, err := holder.Explain(newUser(11, "LUCIA", "SANTIAGO"))
+	assert.True(t, errors.Is(err, ErrEmptyHolder))
+	assert.Empty(t, trace.Steps)
+
+	b, err := json.Marshal(userTree())
+	require.NoError(t, err)
+	require.NoError(t, holder.LoadJSON(b))
+	result, err := holder.Resolve(newUser(11, "LUCIA", "SANTIAGO"))
+	require.NoError(t, err)
+	assert.Equal(t, "node4", result)
+}
+
+// modifyNode4Result returns the json of the tree with the result of node 4 replaced
+func modifyNode4Result(t *testing.T, treeJSON []byte, result string) []byte {
+	aux := struct {
+		Nodes []map[string]interface{} `json:"nodes"`
+		Name  string                   `json:"name"`
+	}{}
+	require.NoError(t, json.Unmarshal(treeJSON, &aux))
+	for _, n := range aux.Nodes {
+		if n["id"].(float64) == 4 {
+			n["result"] = map[string]interface{}{"Value": result, "Type": "string"}
+		}
+	}
+	b, err := json.Marshal(aux)
+	require.NoError(t, err)
+	return b
+}
+
+func TestHolder_ConcurrentResolveAndLoad(t *testing.T) {
+	holder, err := NewHolder(userTree())
+	require.NoError(t, err)
+	b, err := json.Marshal(holder.Tree())
+	require.NoError(t, err)
+	versions := [][]byte{modifyNode4Result(t, b, "v1"), modifyNode4Result(t, b, "v2")}
+
+	var wg sync.WaitGroup
+	done := make(chan struct{})
+	for i := 0; i < 8; i++ {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			for {
+				select {
+				case <-done:
+					return
+				default:
+				}
+				result, err := holder.Resolve(newUser(11, "LUCIA", "SANTIAGO"))
+				if assert.NoError(t, err) {
+					assert.Contains(t, []interface{}{"node4", "v1", "v2"}, result)
+				}
+				_, _, err = holder.Explain(newUser(65, "LUCIA", "SANTIAGO"))
+				assert.NoError(t, err)
+			}
+		}()
+	}
+	for i := 0; i < 100; i++ {
+		require.NoError(t, holder.LoadJSON(versions[i%2]))
+	}
+	close(done)
+	wg.Wait()
+	result, err := holder.Resolve(newUser(11, "LUCIA", "SANTIAGO"))
+	require.NoError(t, err)
+	assert.Equal(t, "v2", result)
+}
+
+func TestHolder_LoadYAML(t *testing.T) {
+	holder, err := NewHolder(userTree())
+	require.NoError(t, err)
+	require.NoError(t, holder.LoadYAML([]byte(`
+name: yamlTree
+root:
+  children:
+    - {default: true, result: {Value: yaml, Type: string}}
+`)))
+	result, err := holder.Resolve(nil)
+	require.NoError(t, err)
+	assert.Equal(t, "yaml", result)
+	assert.Error(t, holder.LoadYAML([]byte(`name: [`)))
+	assert.Equal(t, "yamlTree", holder.Tree().Name)
+}
@@ -0,0 +1,117 @@
+package ddt
+
+import (
+	"encoding/json"
+)
+
+// MarshalNestedJSON encodes the tree in the nested representation, where each
+// node embeds its children instead of referencing its parent id
+func (t *Tree) MarshalNestedJSON() ([]byte, error) {
+	type TreeAlias Tree
+	return json.Marshal(&struct {
+		*TreeAlias
+		Root *nestedNode `json:"root"`
+	}{
+		TreeAlias: (*TreeAlias)(t),
+		Root:      newNestedNode(t.Root),
+	})
+}
+
+// nodeAlias has the fields of Node without its json methods
+type nodeAlias Node
+
+// nestedNode is the json of a node in the nested representation, the parent
+// id is implied by the nesting
+type nestedNode struct {
+	*nodeAlias
+	PreProcessFn string        `json:"preProcessFnName,omitempty"`
+	ParentID     *int          `json:"parentId,omitempty"`
+	Children     []*nestedNode `json:"children,omitempty"`
+}
+
+func newNestedNode(n *Node) *nestedNode {
+	if n == nil {
+		return nil
+	}
+	nested := &nestedNode{nodeAlias: (*nodeAlias)(n), PreProcessFn: n.PreProcessFn.Name}
+	for _, c := range n.Children {
+		nested.Children = append(nested.Children, newNestedNode(c))
+	}
+	return nested
+}
+
+// decodedNode is a node decoded from the nested representation, remembering
+// which ids were present so the missing ones can be assigned
+type decodedNode struct {
+	node        *Node
+	hasID       bool
+	hasParentID bool
+	children    []*decodedNode
+}
+
+// decodeNestedNodes decodes the nested representation into the tree. Nodes
+// without id get the next unused id, the root node gets 0, and nodes without
+// parent id get the id of the node they are nested in.
+func decodeNestedNodes(t *Tree, data json.RawMessage) error {
+	usedIDs := map[int]bool{}
+	root, err := decodeNestedNode(t, data, usedIDs)
+	if err != nil {
+		return err
+	}
+	nextID := 1
+	var assign func(d *decodedNode, parentID int, isRoot bool) error
+	assign = func(d *decodedNode, parentID int, isRoot bool) error {
+		if !d.hasID && !isRoot {
+			for usedIDs[nextID] {
+				nextID++
+			}
+			d.node.ID = nextID
+			usedIDs[nextID] = true
+		}
+		if !d.hasParentID {
+			d.node.ParentID = parentID
+		}
+		if err := addPreprocessFn(t, d.node); err != nil {
+			return err
+		}
+		for _, c := range d.children {
+			if err := assign(c, d.node.ID, false); err != nil {
+				return err
+			}
+			d.node.Children = append(d.node.Children, c.node)
+		}
+		return nil
+	}
+	if err := assign(root, -1, true); err != nil {
+		return err
+	}
+	t.Root = root.node
+	return nil
+}
+
+func decodeNestedNode(t *Tree, data json.RawMessage, usedIDs map[int]bool) (*decodedNode, error) {
+	n := &Node{}
+	if err := n.decode(data, t.Comparers); err != nil {
+		return nil, err
+	}
+	aux := struct {
+		ID       *int              `json:"id"`
+		ParentID *int              `json:"parentId"`
+		Children []json.RawMessage `json:"children"`
+	}{}
+	if err := json.Unmarshal(data, &aux); err != nil {
+		return nil, err
+	}
+	d := &decodedNode{node: n, hasID: aux.ID != nil, hasParentID: aux.ParentID != nil}
+	if d.hasID {
+		usedIDs[n.ID] = true
+	}
+	for _, c := range aux.Children {
+		child, err := decodeNestedNode(t, c, usedIDs)
+		if err != nil {
+			return nil, err
+		}
+		d.children = append(d.children, child)
+	}
+	return d, nil
+}
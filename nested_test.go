@@ -0,0 +1,110 @@
+package ddt
+
+import (
+	"encoding/json"
+	"errors"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestNestedJSON_RoundTrip(t *testing.T) {
+	ut := userTree()
+	b, err := ut.MarshalNestedJSON()
+	require.NoError(t, err)
+	assert.Contains(t, string(b), `"root":{"id":0,"preProcessFnArgs":[{"Value":"UnderAge","Type":"string"}],"preProcessFnName":"CallStructMethod","children":[`)
+	assert.NotContains(t, string(b), "parentId")
+
+	var decoded Tree
+	require.NoError(t, json.Unmarshal(b, &decoded))
+	flat, err := json.Marshal(ut)
+	require.NoError(t, err)
+	decodedFlat, err := json.Marshal(&decoded)
+	require.NoError(t, err)
+	assert.JSONEq(t, string(flat), string(decodedFlat))
+}
+
+func TestNestedJSON_AutoAssignIDs(t *testing.T) {
+	treeJSON := []byte(`{
+  "name": "ageTree",
+  "root": {
+    "preProcessFnName": "GetStructAttribute",
+    "preProcessFnArgs": [{"Value": "Age", "Type": "string"}],
+    "children": [
+      {
+        "id": 1,
+        "comparer": {"type": "lt", "equal": false},
+        "valueToCompare": {"Value": 18, "Type": "int"},
+        "result": {"Value": "minor", "Type": "string"}
+      },
+      {
+        "default": true,
+        "preProcessFnName": "GetStructAttribute",
+        "preProcessFnArgs": [{"Value": "FirstName", "Type": "string"}],
+        "children": [
+          {"comparer": {"type": "eq"}, "valueToCompare": {"Value": "LUCIA", "Type": "string"}, "result": {"Value": "lucia", "Type": "string"}},
+          {"default": true, "result": {"Value": "adult", "Type": "string"}}
+        ]
+      }
+    ]
+  }
+}`)
+	var tree Tree
+	require.NoError(t, json.Unmarshal(treeJSON, &tree))
+	assert.Equal(t, "ageTree", tree.Name)
+	assert.Equal(t, -1, tree.Root.ParentID)
+	adult := tree.Root.Children[1]
+	assert.Equal(t, 2, adult.ID)
+	assert.Equal(t, 0, adult.ParentID)
+	assert.Equal(t, 3, adult.Children[0].ID)
+	assert.Equal(t, 2, adult.Children[0].ParentID)
+	assert.Equal(t, 4, adult.Children[1].ID)
+
+	testCases := map[string]struct {
+		input    *user
+		expected string
+	}{
+		"minor": {input: newUser(10, "LUCIA", "SANTIAGO"), expected: "minor"},
+		"lucia": {input: newUser(20, "LUCIA", "SANTIAGO"), expected: "lucia"},
+		"adult": {input: newUser(20, "SANTIAGO", "LUCIA"), expected: "adult"},
+	}
+	for name, tc := range testCases {
+		t.Run(name, func(t *testing.T) {
+			result, err := ResolveTree(&tree, tc.input)
+			require.NoError(t, err)
+			assert.Equal(t, tc.expected, result)
+		})
+	}
+}
+
+func TestNestedJSON_Errors(t *testing.T) {
+	testCases := map[string]struct {
+		input    string
+		expected string
+	}{
+		"wrong parent id": {
+			input:    `{"root":{"children":[{"id":1,"parentId":5,"comparer":{"type":"eq"},"valueToCompare":{"Value":1,"Type":"int"},"result":{"Value":1,"Type":"int"}}]}}`,
+			expected: "invalid tree: node 1: parent id 5 does not match actual parent 0",
+		},
+		"duplicate id": {
+			input:    `{"root":{"children":[{"id":1,"default":true,"result":{"Value":1,"Type":"int"}},{"id":1,"comparer":{"type":"eq"},"valueToCompare":{"Value":1,"Type":"int"},"result":{"Value":1,"Type":"int"}}]}}`,
+			expected: "invalid tree: node 1: duplicate node id",
+		},
+		"unknown function": {
+			input:    `{"root":{"preProcessFnName":"Unknown","children":[{"default":true,"result":{"Value":1,"Type":"int"}}]}}`,
+			expected: `node 0: pre process function "Unknown" not found`,
+		},
+	}
+	for name, tc := range testCases {
+		t.Run(name, func(t *testing.T) {
+			ut := userTree()
+			err := json.Unmarshal([]byte(tc.input), ut)
+			assert.EqualError(t, err, tc.expected)
+			assert.Equal(t, "userTree", ut.Name)
+		})
+	}
+	var validationErr *ValidationError
+	err := json.Unmarshal([]byte(`{"root":{"children":[{"comparer":{"type":"eq"}}]}}`), userTree())
+	assert.True(t, errors.As(err, &validationErr))
+}
@@ -1,15 +1,18 @@
 package ddt
 
 import (
+	"context"
 	"encoding/json"
-	"errors"
+
 	"github.com/sgrodriguez/ddt/compare"
+	"github.com/sgrodriguez/ddt/expr"
 
 	"github.com/sgrodriguez/ddt/function"
 	"github.com/sgrodriguez/ddt/value"
 )
 
-// Comparer interface
+// Comparer interface, comparers decoded from json are created by the
+// ComparerFactory of their type and must marshal it (ie {"type": "eq"})
 type Comparer interface {
 	Compare(a, b interface{}) bool
 }
@@ -22,31 +25,88 @@ type Node struct {
 	ParentID       int                   `json:"parentId"`
 	PreProcessFn   function.PreProcessFn `json:"-"`
 	PreProcessArgs []*value.Value        `json:"preProcessFnArgs,omitempty"`
-	Comparer       Comparer              `json:"comparer,omitempty"`
-	ValueToCompare *value.Value          `json:"valueToCompare,omitempty"`
-	Result         *value.Value          `json:"result,omitempty"`
+	// Pipeline is an ordered list of pre process functions used instead of
+	// PreProcessFn, each one receives the value returned by the previous one.
+	Pipeline []*PipelineStep `json:"pipeline,omitempty"`
+	// Expression is evaluated over the input instead of PreProcessFn (ie
+	// `input.Age * 12 + input.Months`), see the expr package.
+	Expression     string       `json:"expression,omitempty"`
+	Comparer       Comparer     `json:"comparer,omitempty"`
+	ValueToCompare *value.Value `json:"valueToCompare,omitempty"`
+	Result         *value.Value `json:"result,omitempty"`
+	// Default marks the node as the branch taken when no sibling matches,
+	// its Comparer and ValueToCompare are not used.
+	Default bool `json:"default,omitempty"`
+
+	// program is the Expression compiled decoding the node or creating the tree
+	program *expr.Program
 }
 
-// NextNode ...
+// NextNode resolves the subtree of n for the given input, taking the default
+// child of the nodes without a matching child. The node does not know its
+// tree, so the tree DefaultResult and NumericCoercion are not applied, use
+// ResolveTree for them. A nil node fails with ErrMissingRoot.
 func (n *Node) NextNode(input interface{}) (interface{}, error) {
+	if n == nil {
+		return nil, ErrMissingRoot
+	}
+	return n.resolve(&resolution{ctx: context.Background()}, input, 0)
+}
+
+// resolution holds the state shared by every node while resolving a tree
+type resolution struct {
+	ctx     context.Context
+	trace   *Trace
+	numeric bool
+}
+
+// compare calls the comparer, with numeric coercion when it is enabled
+func (r *resolution) compare(c Comparer, a, b interface{}) bool {
+	if nc, ok := c.(compare.NumericComparer); ok && r.numeric {
+		return nc.CompareNumeric(a, b)
+	}
+	return c.Compare(a, b)
+}
+
+func (n *Node) resolve(r *resolution, input interface{}, depth int) (interface{}, error) {
+	if err := r.ctx.Err(); err != nil {
+		return nil, err
+	}
+	step := r.trace.visit(n)
 	if len(n.Children) == 0 {
+		if n.Result == nil {
+			return nil, &InvalidLeafError{NodeID: n.ID, Depth: depth}
+		}
 		return n.Result.Value, nil
 	}
-	resValue, err := getValueToCompare(input, n.PreProcessFn, n.PreProcessArgs)
+	resValue, err := n.preProcess(r.ctx, input, depth)
 	if err != nil {
 		return nil, err
 	}
+	step.setValue(resValue)
+	var defaultChild *Node
 	for _, c := range n.Children {
-		if c.Comparer.Compare(resValue, c.ValueToCompare.Value) {
-			return c.NextNode(input)
+		if c.Default {
+			defaultChild = c
+			continue
+		}
+		b := interfaceValue(c.ValueToCompare)
+		matched := r.compare(c.Comparer, resValue, b)
+		step.compared(c.ID, c.Comparer, resValue, b, matched)
+		if matched {
+			return c.resolve(r, input, depth+1)
 		}
 	}
-	return nil, errors.New("value not found when comparing with all children nodes")
+	if defaultChild != nil {
+		step.tookDefault()
+		return defaultChild.resolve(r, input, depth+1)
+	}
+	return nil, &NoMatchingChildError{NodeID: n.ID, Depth: depth, Value: resValue}
 }
 
-func getValueToCompare(input interface{}, fn function.PreProcessFn, args []*value.Value) (interface{}, error) {
+func getValueToCompare(ctx context.Context, input interface{}, fn function.PreProcessFn, args []*value.Value) (interface{}, error) {
 	if !fn.Empty() {
-		resValue, err := fn.Function(input, value.GetValueInterfaces(args)...)
+		resValue, err := fn.Call(ctx, input, value.GetValueInterfaces(args)...)
 		if err != nil {
 			return nil, err
 		}
@@ -56,6 +116,15 @@ func getValueToCompare(input interface{}, fn function.PreProcessFn, args []*valu
 	return input, nil
 }
 
+// interfaceValue returns the value of v, nil when v is nil like the value to
+// compare of nodes with composite comparers
+func interfaceValue(v *value.Value) interface{} {
+	if v == nil {
+		return nil
+	}
+	return v.Value
+}
+
 // MarshalJSON ...
 func (n *Node) MarshalJSON() ([]byte, error) {
 	type NodeAlias Node
@@ -70,6 +139,12 @@ func (n *Node) MarshalJSON() ([]byte, error) {
 
 // UnmarshalJSON ...
 func (n *Node) UnmarshalJSON(data []byte) error {
+	return n.decode(data, nil)
+}
+
+// decode decodes the node creating its comparer with the given factories,
+// falling back to the registered ones
+func (n *Node) decode(data []byte, factories map[string]ComparerFactory) error {
 	type NodeAlias Node
 	nodeAlias := &struct {
 		PreProcessFn string          `json:"preProcessFnName"`
@@ -83,31 +158,50 @@ func (n *Node) UnmarshalJSON(data []byte) error {
 	}
 	n.PreProcessFn = function.PreProcessFn{Name: nodeAlias.PreProcessFn}
 	if nodeAlias.Comparer != nil {
-		comp, err := createComparatorFromJSON(nodeAlias.Comparer)
+		comp, err := decodeComparer(nodeAlias.Comparer, factories)
 		if err != nil {
 			return err
 		}
 		n.Comparer = comp
 	}
+	n.precompile()
 	return nil
 }
 
-// CreateComparatorFromJSON ...
-func createComparatorFromJSON(message json.RawMessage) (Comparer, error) {
-	aux := &struct {
-		Comp  string `json:"type"`
-		Equal bool   `json:"equal"`
-	}{}
-	if err := json.Unmarshal(message, aux); err != nil {
-		return nil, err
+// precompile compiles the expression and the regex patterns compared by the
+// node, it is called decoding the node and creating a tree so they are
+// compiled once instead of on every resolution. Invalid expressions and
+// patterns are left to Validate.
+func (n *Node) precompile() {
+	if n.Expression != "" {
+		if program, err := n.expression(); err == nil && n.program != program {
+			n.program = program
+		}
 	}
-	switch aux.Comp {
-	case "eq":
-		return &compare.Equal{}, nil
-	case "lt":
-		return &compare.Lesser{Equal: aux.Equal}, nil
-	case "gt":
-		return &compare.Greater{Equal: aux.Equal}, nil
+	precompileComparer(n.Comparer, n.ValueToCompare)
+}
+
+func precompileComparer(c Comparer, val *value.Value) {
+	switch comp := c.(type) {
+	case *compare.And:
+		precompileOperands(comp.Operands)
+	case *compare.Or:
+		precompileOperands(comp.Operands)
+	case *compare.Not:
+		if comp.Operand != nil {
+			precompileComparer(comp.Operand.Comparer, comp.Operand.Value)
+		}
+	case *compare.Regex:
+		if pattern, ok := interfaceValue(val).(string); ok {
+			_ = comp.Compile(pattern)
+		}
+	}
+}
+
+func precompileOperands(operands []*compare.Operand) {
+	for _, op := range operands {
+		if op != nil {
+			precompileComparer(op.Comparer, op.Value)
+		}
 	}
-	return nil, errors.New("invalid comparer")
 }
@@ -0,0 +1,90 @@
+package ddt
+
+import (
+	"context"
+	"encoding/json"
+
+	"github.com/sgrodriguez/ddt/function"
+	"github.com/sgrodriguez/ddt/value"
+)
+
+// PipelineStep is a pre process function of a node pipeline with its args,
+// each step receives the value returned by the previous one
+type PipelineStep struct {
+	PreProcessFn   function.PreProcessFn `json:"-"`
+	PreProcessArgs []*value.Value        `json:"preProcessFnArgs,omitempty"`
+}
+
+// MarshalJSON ...
+func (s *PipelineStep) MarshalJSON() ([]byte, error) {
+	type stepAlias PipelineStep
+	return json.Marshal(&struct {
+		PreProcessFn string `json:"preProcessFnName"`
+		*stepAlias
+	}{
+		PreProcessFn: s.PreProcessFn.Name,
+		stepAlias:    (*stepAlias)(s),
+	})
+}
+
+// UnmarshalJSON ...
+func (s *PipelineStep) UnmarshalJSON(data []byte) error {
+	type stepAlias PipelineStep
+	aux := &struct {
+		PreProcessFn string `json:"preProcessFnName"`
+		*stepAlias
+	}{
+		stepAlias: (*stepAlias)(s),
+	}
+	if err := json.Unmarshal(data, aux); err != nil {
+		return err
+	}
+	s.PreProcessFn = function.PreProcessFn{Name: aux.PreProcessFn}
+	return nil
+}
+
+// preProcess returns the value to compare of the node, produced by its
+// PreProcessFn, by running its pipeline or by evaluating its expression
+func (n *Node) preProcess(ctx context.Context, input interface{}, depth int) (interface{}, error) {
+	if n.Expression != "" {
+		program, err := n.expression()
+		var v interface{}
+		if err == nil {
+			v, err = program.EvalContext(ctx, input)
+		}
+		if err != nil {
+			return nil, &PreProcessError{NodeID: n.ID, Depth: depth, Expression: n.Expression, Value: input, Err: err}
+		}
+		return v, nil
+	}
+	if len(n.Pipeline) == 0 {
+		v, err := getValueToCompare(ctx, input, n.PreProcessFn, n.PreProcessArgs)
+		if err != nil {
+			return nil, &PreProcessError{NodeID: n.ID, Depth: depth, PreProcessFn: n.PreProcessFn.Name, Value: input, Err: err}
+		}
+		return v, nil
+	}
+	v := input
+	for _, s := range n.Pipeline {
+		res, err := getValueToCompare(ctx, v, s.PreProcessFn, s.PreProcessArgs)
+		if err != nil {
+			return nil, &PreProcessError{NodeID: n.ID, Depth: depth, PreProcessFn: s.PreProcessFn.Name, Value: v, Err: err}
+		}
+		v = res
+	}
+	return v, nil
+}
+
+// pipelineNames returns the names of the pipeline functions
+func (n *Node) pipelineNames() []string {
+	if len(n.Pipeline) == 0 {
+		return nil
+	}
+	names := make([]string, len(n.Pipeline))
+	for i, s := range n.Pipeline {
+		if s != nil {
+			names[i] = s.PreProcessFn.Name
+		}
+	}
+	return names
+}
@@ -0,0 +1,158 @@
+package ddt
+
+import (
+	"encoding/json"
+	"errors"
+	"strings"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"github.com/sgrodriguez/ddt/function"
+)
+
+type customer struct {
+	Email string
+}
+
+var pipelineFns = []function.PreProcessFn{
+	{Name: "Lower", Function: func(str interface{}, args ...interface{}) (interface{}, error) {
+		s, ok := str.(string)
+		if !ok {
+			return nil, errors.New("lower: not a string")
+		}
+		return strings.ToLower(s), nil
+	}},
+	{Name: "After", Function: func(str interface{}, args ...interface{}) (interface{}, error) {
+		s, _ := str.(string)
+		sep, _ := args[0].(string)
+		idx := strings.LastIndex(s, sep)
+		if idx < 0 {
+			return nil, errors.New("after: separator not found")
+		}
+		return s[idx+len(sep):], nil
+	}},
+}
+
+const pipelineTreeJSON = `{"name": "emailDomain", "root": {
+	"pipeline": [
+		{"preProcessFnName": "GetStructAttribute", "preProcessFnArgs": [{"Value": "Email", "Type": "string"}]},
+		{"preProcessFnName": "Lower"},
+		{"preProcessFnName": "After", "preProcessFnArgs": [{"Value": "@", "Type": "string"}]}
+	],
+	"children": [
+		{"comparer": {"type": "eq"}, "valueToCompare": {"Value": "example.com", "Type": "string"}, "result": {"Value": "internal", "Type": "string"}},
+		{"default": true, "result": {"Value": "external", "Type": "string"}}
+	]}}`
+
+func pipelineTree(t *testing.T) *Tree {
+	tree, err := NewTree("emailDomain", nil, pipelineFns...)
+	require.NoError(t, err)
+	require.NoError(t, json.Unmarshal([]byte(pipelineTreeJSON), tree))
+	return tree
+}
+
+func TestPipeline_Resolve(t *testing.T) {
+	tree := pipelineTree(t)
+	evaluator, err := Compile(tree)
+	require.NoError(t, err)
+	tests := map[string]struct {
+		input    *customer
+		expected interface{}
+	}{
+		"internal":     {input: &customer{Email: "Ana@Example.COM"}, expected: "internal"},
+		"external":     {input: &customer{Email: "bob@gmail.com"}, expected: "external"},
+		"last at sign": {input: &customer{Email: "a@b@example.com"}, expected: "internal"},
+	}
+	for name, tc := range tests {
+		t.Run(name, func(t *testing.T) {
+			res, err := ResolveTree(tree, tc.input)
+			require.NoError(t, err)
+			assert.Equal(t, tc.expected, res)
+			res, err = evaluator.Resolve(tc.input)
+			require.NoError(t, err)
+			assert.Equal(t, tc.expected, res)
+		})
+	}
+	t.Run("failing step", func(t *testing.T) {
+		_, err := ResolveTree(tree, &customer{Email: "no-domain"})
+		assert.EqualError(t, err, `node 0 (depth 0): pre process function "After" failed: after: separator not found`)
+		var ppErr *PreProcessError
+		require.True(t, errors.As(err, &ppErr))
+		assert.Equal(t, "no-domain", ppErr.Value)
+		_, err = evaluator.Resolve(&customer{Email: "no-domain"})
+		assert.EqualError(t, err, `node 0 (depth 0): pre process function "After" failed: after: separator not found`)
+	})
+	t.Run("trace", func(t *testing.T) {
+		_, trace, err := ExplainTree(tree, &customer{Email: "Ana@Example.COM"})
+		require.NoError(t, err)
+		assert.Equal(t, []string{"GetStructAttribute", "Lower", "After"}, trace.Steps[0].Pipeline)
+		assert.Equal(t, "example.com", trace.Steps[0].Value)
+	})
+}
+
+func TestPipeline_JSON(t *testing.T) {
+	tree := pipelineTree(t)
+	for name, marshal := range map[string]func() ([]byte, error){
+		"flat":   func() ([]byte, error) { return json.Marshal(tree) },
+		"nested": tree.MarshalNestedJSON,
+	} {
+		t.Run(name, func(t *testing.T) {
+			b, err := marshal()
+			require.NoError(t, err)
+			decoded, err := NewTree("decoded", nil, pipelineFns...)
+			require.NoError(t, err)
+			require.NoError(t, json.Unmarshal(b, decoded))
+			require.Len(t, decoded.Root.Pipeline, 3)
+			assert.Equal(t, "After", decoded.Root.Pipeline[2].PreProcessFn.Name)
+			assert.True(t, decoded.Root.Pipeline[2].PreProcessFn.Defined())
+			assert.Equal(t, "@", decoded.Root.Pipeline[2].PreProcessArgs[0].Value)
+			res, err := ResolveTree(decoded, &customer{Email: "x@example.com"})
+			require.NoError(t, err)
+			assert.Equal(t, "internal", res)
+		})
+	}
+	t.Run("unknown step function", func(t *testing.T) {
+		var tree Tree
+		err := json.Unmarshal([]byte(pipelineTreeJSON), &tree)
+		assert.EqualError(t, err, `node 0: pipeline step 2: pre process function "After" not found`)
+		var nodeErr *NodeError
+		assert.True(t, errors.As(err, &nodeErr))
+	})
+}
+
+func TestPipeline_Validate(t *testing.T) {
+	tests := map[string]struct {
+		modify   func(n *Node)
+		expected string
+	}{
+		"pipeline and function": {
+			modify:   func(n *Node) { n.PreProcessFn = pipelineFns[0] },
+			expected: "invalid tree: node 0: pre process function and pipeline are mutually exclusive",
+		},
+		"step without function": {
+			modify:   func(n *Node) { n.Pipeline[1] = &PipelineStep{} },
+			expected: "invalid tree: node 0: pipeline step 1 has no pre process function",
+		},
+		"nil step": {
+			modify:   func(n *Node) { n.Pipeline[0] = nil },
+			expected: "invalid tree: node 0: pipeline step 0 has no pre process function",
+		},
+		"step without implementation": {
+			modify:   func(n *Node) { n.Pipeline[2].PreProcessFn = function.PreProcessFn{Name: "After"} },
+			expected: `invalid tree: node 0: pipeline step 2: pre process function "After" has no implementation`,
+		},
+	}
+	for name, tc := range tests {
+		t.Run(name, func(t *testing.T) {
+			tree := pipelineTree(t)
+			tc.modify(tree.Root)
+			assert.EqualError(t, tree.Validate(), tc.expected)
+		})
+	}
+}
+
+func TestPipeline_Label(t *testing.T) {
+	assert.Equal(t, `#0 GetStructAttribute("Email") | Lower() | After("@")`, nodeLabel(pipelineTree(t).Root))
+}
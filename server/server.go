@@ -0,0 +1,231 @@
+// Package server exposes decision trees through a json HTTP API.
+//
+// The API serves the trees by name:
+//
+//	POST /trees/{name}/resolve  resolves the posted json input, ?trace=true adds the trace
+//	GET  /trees/{name}          returns the current tree definition
+//	PUT  /trees/{name}          validates and stores a new version of the tree
+package server
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"errors"
+	"io/ioutil"
+	"net/http"
+	"strings"
+	"sync"
+
+	"github.com/sgrodriguez/ddt"
+	"github.com/sgrodriguez/ddt/function"
+)
+
+// Server is an http.Handler serving named trees. Each tree is kept in a
+// ddt.Holder, so resolutions are not blocked while new versions are stored.
+type Server struct {
+	mu      sync.RWMutex
+	holders map[string]*ddt.Holder
+	// MaxBodyBytes limits the size of the request bodies
+	MaxBodyBytes int64
+	// Functions and Comparers are used decoding the trees created by a PUT
+	// like Tree.Functions and Tree.Comparers, new versions of a served tree
+	// keep the ones of the current version
+	Functions map[string]function.PreProcessFn
+	Comparers map[string]ddt.ComparerFactory
+}
+
+// New creates a server for the given trees, served by their Name
+func New(trees ...*ddt.Tree) (*Server, error) {
+	s := &Server{holders: map[string]*ddt.Holder{}, MaxBodyBytes: 1 << 20}
+	for _, t := range trees {
+		if err := s.Add(t); err != nil {
+			return nil, err
+		}
+	}
+	return s, nil
+}
+
+// Add adds or replaces the tree served by its Name
+func (s *Server) Add(t *ddt.Tree) error {
+	if t.Name == "" {
+		return errors.New("server: tree without name")
+	}
+	holder, err := ddt.NewHolder(t)
+	if err != nil {
+		return err
+	}
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	s.holders[t.Name] = holder
+	return nil
+}
+
+// Holder returns the holder of the named tree
+func (s *Server) Holder(name string) (*ddt.Holder, bool) {
+	s.mu.RLock()
+	defer s.mu.RUnlock()
+	h, ok := s.holders[name]
+	return h, ok
+}
+
+// ResolveResponse is the response of the resolve endpoint
+type ResolveResponse struct {
+	Result interface{} `json:"result"`
+	Trace  *ddt.Trace  `json:"trace,omitempty"`
+}
+
+// ErrorResponse is the response of any failed request, a failed resolution
+// with ?trace=true has the trace of the path followed until the failure
+type ErrorResponse struct {
+	Error string     `json:"error"`
+	Trace *ddt.Trace `json:"trace,omitempty"`
+}
+
+// ServeHTTP implements http.Handler
+func (s *Server) ServeHTTP(w http.ResponseWriter, r *http.Request) {
+	parts := strings.Split(strings.Trim(r.URL.Path, "/"), "/")
+	if len(parts) < 2 || len(parts) > 3 || parts[0] != "trees" || parts[1] == "" {
+		writeError(w, http.StatusNotFound, errors.New("not found"))
+		return
+	}
+	name := parts[1]
+	holder, ok := s.Holder(name)
+	if len(parts) == 3 {
+		if parts[2] != "resolve" {
+			writeError(w, http.StatusNotFound, errors.New("not found"))
+			return
+		}
+		if r.Method != http.MethodPost {
+			methodNotAllowed(w, http.MethodPost)
+			return
+		}
+		if !ok {
+			writeError(w, http.StatusNotFound, errors.New("tree not found"))
+			return
+		}
+		s.resolve(w, r, holder)
+		return
+	}
+	switch r.Method {
+	case http.MethodGet:
+		if !ok {
+			writeError(w, http.StatusNotFound, errors.New("tree not found"))
+			return
+		}
+		writeJSON(w, http.StatusOK, holder.Tree())
+	case http.MethodPut:
+		s.store(w, r, name, holder)
+	default:
+		methodNotAllowed(w, http.MethodGet, http.MethodPut)
+	}
+}
+
+func (s *Server) resolve(w http.ResponseWriter, r *http.Request, holder *ddt.Holder) {
+	body, err := s.readBody(w, r)
+	if err != nil {
+		writeError(w, http.StatusBadRequest, err)
+		return
+	}
+	input, err := DecodeInput(body)
+	if err != nil {
+		writeError(w, http.StatusBadRequest, err)
+		return
+	}
+	var res ResolveResponse
+	if r.URL.Query().Get("trace") == "true" {
+		res.Result, res.Trace, err = holder.ExplainContext(r.Context(), input)
+	} else {
+		res.Result, err = holder.ResolveContext(r.Context(), input)
+	}
+	if err != nil {
+		writeJSON(w, resolveStatus(err), ErrorResponse{Error: err.Error(), Trace: res.Trace})
+		return
+	}
+	writeJSON(w, http.StatusOK, res)
+}
+
+// statusClientClosedRequest is the non standard status used when the client
+// goes away before the response is written
+const statusClientClosedRequest = 499
+
+// resolveStatus maps the resolution errors to a status code, inputs not
+// matching any rule or failing to pre process are unprocessable while broken
+// trees are server errors. The context errors are checked first, they may be
+// wrapped by a pre process function, and are not server errors.
+func resolveStatus(err error) int {
+	switch {
+	case errors.Is(err, context.Canceled):
+		return statusClientClosedRequest
+	case errors.Is(err, context.DeadlineExceeded):
+		return http.StatusGatewayTimeout
+	case errors.Is(err, ddt.ErrNoMatchingChild):
+		return http.StatusUnprocessableEntity
+	case errors.Is(err, function.ErrInvalidArgs):
+		// the args are part of the tree, so the tree is broken
+		return http.StatusInternalServerError
+	case errors.Is(err, ddt.ErrPreProcess):
+		return http.StatusUnprocessableEntity
+	}
+	return http.StatusInternalServerError
+}
+
+func (s *Server) store(w http.ResponseWriter, r *http.Request, name string, holder *ddt.Holder) {
+	body, err := s.readBody(w, r)
+	if err != nil {
+		writeError(w, http.StatusBadRequest, err)
+		return
+	}
+	tree := &ddt.Tree{Functions: s.Functions, Comparers: s.Comparers}
+	if holder != nil {
+		tree.Functions = holder.Tree().Functions
+		tree.Comparers = holder.Tree().Comparers
+	}
+	if err := json.Unmarshal(body, tree); err != nil {
+		writeError(w, http.StatusBadRequest, err)
+		return
+	}
+	// the tree is served by the name of the path
+	tree.Name = name
+	if holder != nil {
+		err = holder.Store(tree)
+	} else {
+		err = s.Add(tree)
+	}
+	if err != nil {
+		writeError(w, http.StatusBadRequest, err)
+		return
+	}
+	writeJSON(w, http.StatusOK, tree)
+}
+
+func (s *Server) readBody(w http.ResponseWriter, r *http.Request) ([]byte, error) {
+	return ioutil.ReadAll(http.MaxBytesReader(w, r.Body, s.MaxBodyBytes))
+}
+
+// DecodeInput decodes a json input, the numbers are decoded as int when they
+// are integers fitting an int and as float64 otherwise
+func DecodeInput(data []byte) (interface{}, error) {
+	var input interface{}
+	dec := json.NewDecoder(bytes.NewReader(data))
+	dec.UseNumber()
+	if err := dec.Decode(&input); err != nil {
+		return nil, err
+	}
+	return function.NormalizeJSON(input), nil
+}
+
+func methodNotAllowed(w http.ResponseWriter, allowed ...string) {
+	w.Header().Set("Allow", strings.Join(allowed, ", "))
+	writeError(w, http.StatusMethodNotAllowed, errors.New("method not allowed"))
+}
+
+func writeError(w http.ResponseWriter, status int, err error) {
+	writeJSON(w, status, ErrorResponse{Error: err.Error()})
+}
+
+func writeJSON(w http.ResponseWriter, status int, v interface{}) {
+	w.Header().Set("Content-Type", "application/json")
+	w.WriteHeader(status)
+	_ = json.NewEncoder(w).Encode(v)
+}
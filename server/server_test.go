@@ -0,0 +1,200 @@
+package server
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"github.com/sgrodriguez/ddt"
+	"github.com/sgrodriguez/ddt/compare"
+	"github.com/sgrodriguez/ddt/function"
+)
+
+const ageTree = `{
+  "name": "ageTree",
+  "root": {
+    "children": [
+      {"comparer": {"type": "lt", "equal": false}, "valueToCompare": {"Value": 18, "Type": "int"}, "result": {"Value": "minor", "Type": "string"}},
+      {"comparer": {"type": "gt", "equal": true}, "valueToCompare": {"Value": 18.5, "Type": "float64"}, "result": {"Value": "adult", "Type": "string"}}
+    ]
+  }
+}`
+
+func newTestServer(t *testing.T) *Server {
+	tree := &ddt.Tree{}
+	require.NoError(t, json.Unmarshal([]byte(ageTree), tree))
+	s, err := New(tree)
+	require.NoError(t, err)
+	return s
+}
+
+func do(s http.Handler, method, path, body string) *httptest.ResponseRecorder {
+	rec := httptest.NewRecorder()
+	s.ServeHTTP(rec, httptest.NewRequest(method, path, strings.NewReader(body)))
+	return rec
+}
+
+func TestResolve(t *testing.T) {
+	s := newTestServer(t)
+	testCases := map[string]struct {
+		path     string
+		body     string
+		status   int
+		expected string
+	}{
+		"minor":           {path: "/trees/ageTree/resolve", body: `10`, status: http.StatusOK, expected: `{"result":"minor"}`},
+		"adult float":     {path: "/trees/ageTree/resolve", body: `20.5`, status: http.StatusOK, expected: `{"result":"adult"}`},
+		"no match":        {path: "/trees/ageTree/resolve", body: `"x"`, status: http.StatusUnprocessableEntity, expected: `{"error":"node 0 (depth 0): value not found when comparing with all children nodes"}`},
+		"invalid input":   {path: "/trees/ageTree/resolve", body: `{`, status: http.StatusBadRequest, expected: `{"error":"unexpected EOF"}`},
+		"unknown tree":    {path: "/trees/unknown/resolve", body: `10`, status: http.StatusNotFound, expected: `{"error":"tree not found"}`},
+		"unknown path":    {path: "/trees/ageTree/other", body: `10`, status: http.StatusNotFound, expected: `{"error":"not found"}`},
+		"root path":       {path: "/", body: `10`, status: http.StatusNotFound, expected: `{"error":"not found"}`},
+		"trailing slash":  {path: "/trees/ageTree/resolve/", body: `10`, status: http.StatusOK, expected: `{"result":"minor"}`},
+		"trace requested": {path: "/trees/ageTree/resolve?trace=true", body: `10`, status: http.StatusOK},
+	}
+	for name, tc := range testCases {
+		t.Run(name, func(t *testing.T) {
+			rec := do(s, http.MethodPost, tc.path, tc.body)
+			assert.Equal(t, tc.status, rec.Code)
+			assert.Equal(t, "application/json", rec.Header().Get("Content-Type"))
+			if tc.expected != "" {
+				assert.JSONEq(t, tc.expected, rec.Body.String())
+			}
+			if !strings.Contains(tc.path, "?") {
+				// tracing does not change the outcome of the resolution
+				traced := do(s, http.MethodPost, tc.path+"?trace=true", tc.body)
+				assert.Equal(t, rec.Code, traced.Code)
+				assert.Equal(t, withoutTrace(t, rec), withoutTrace(t, traced))
+			}
+		})
+	}
+	rec := do(s, http.MethodPost, "/trees/ageTree/resolve?trace=true", `10`)
+	res := struct {
+		Result string
+		Trace  struct {
+			Steps []struct {
+				NodeID int
+			}
+		}
+	}{}
+	require.NoError(t, json.Unmarshal(rec.Body.Bytes(), &res))
+	assert.Equal(t, "minor", res.Result)
+	assert.Len(t, res.Trace.Steps, 2)
+
+	rec = do(s, http.MethodPost, "/trees/ageTree/resolve?trace=true", `"x"`)
+	assert.Equal(t, http.StatusUnprocessableEntity, rec.Code)
+	res.Trace.Steps = nil
+	require.NoError(t, json.Unmarshal(rec.Body.Bytes(), &res))
+	assert.Len(t, res.Trace.Steps, 1, "failed resolutions have the trace")
+
+	rec = do(s, http.MethodGet, "/trees/ageTree/resolve", "")
+	assert.Equal(t, http.StatusMethodNotAllowed, rec.Code)
+	assert.Equal(t, http.MethodPost, rec.Header().Get("Allow"))
+
+	ctx, cancel := context.WithCancel(context.Background())
+	cancel()
+	rec = httptest.NewRecorder()
+	s.ServeHTTP(rec, httptest.NewRequest(http.MethodPost, "/trees/ageTree/resolve", strings.NewReader(`10`)).WithContext(ctx))
+	assert.Equal(t, statusClientClosedRequest, rec.Code)
+}
+
+// withoutTrace returns the decoded response without its trace
+func withoutTrace(t *testing.T, rec *httptest.ResponseRecorder) map[string]interface{} {
+	var res map[string]interface{}
+	require.NoError(t, json.Unmarshal(rec.Body.Bytes(), &res))
+	delete(res, "trace")
+	return res
+}
+
+func TestResolveStatus(t *testing.T) {
+	preProcessErr := func(err error) error {
+		return &ddt.PreProcessError{PreProcessFn: "Split", Err: err}
+	}
+	testCases := map[string]struct {
+		err    error
+		status int
+	}{
+		"no match":      {err: &ddt.NoMatchingChildError{}, status: http.StatusUnprocessableEntity},
+		"invalid input": {err: preProcessErr(fmt.Errorf("split %w", function.ErrInvalidInput)), status: http.StatusUnprocessableEntity},
+		"invalid args":  {err: preProcessErr(fmt.Errorf("split %w", function.ErrInvalidArgs)), status: http.StatusInternalServerError},
+		"invalid leaf":  {err: &ddt.InvalidLeafError{}, status: http.StatusInternalServerError},
+		"canceled":      {err: context.Canceled, status: statusClientClosedRequest},
+		"deadline":      {err: preProcessErr(context.DeadlineExceeded), status: http.StatusGatewayTimeout},
+	}
+	for name, tc := range testCases {
+		t.Run(name, func(t *testing.T) {
+			assert.Equal(t, tc.status, resolveStatus(tc.err))
+		})
+	}
+}
+
+func TestGetAndPutTree(t *testing.T) {
+	s := newTestServer(t)
+	rec := do(s, http.MethodGet, "/trees/ageTree", "")
+	require.Equal(t, http.StatusOK, rec.Code)
+	tree := &ddt.Tree{}
+	require.NoError(t, json.Unmarshal(rec.Body.Bytes(), tree))
+	assert.Equal(t, "ageTree", tree.Name)
+	assert.Equal(t, http.StatusNotFound, do(s, http.MethodGet, "/trees/unknown", "").Code)
+	assert.Equal(t, http.StatusMethodNotAllowed, do(s, http.MethodDelete, "/trees/ageTree", "").Code)
+
+	t.Run("invalid version is rejected", func(t *testing.T) {
+		rec := do(s, http.MethodPut, "/trees/ageTree", `{"nodes":[{"id":1,"parentId":0}]}`)
+		assert.Equal(t, http.StatusBadRequest, rec.Code)
+		assert.JSONEq(t, `{"error":"missing root node"}`, rec.Body.String())
+		rec = do(s, http.MethodPost, "/trees/ageTree/resolve", `10`)
+		assert.JSONEq(t, `{"result":"minor"}`, rec.Body.String())
+	})
+	t.Run("new version", func(t *testing.T) {
+		newVersion := strings.Replace(ageTree, `"minor"`, `"child"`, 1)
+		rec := do(s, http.MethodPut, "/trees/ageTree", newVersion)
+		require.Equal(t, http.StatusOK, rec.Code)
+		rec = do(s, http.MethodPost, "/trees/ageTree/resolve", `10`)
+		assert.JSONEq(t, `{"result":"child"}`, rec.Body.String())
+	})
+	t.Run("new tree named by the path", func(t *testing.T) {
+		rec := do(s, http.MethodPut, "/trees/otherTree", ageTree)
+		require.Equal(t, http.StatusOK, rec.Code)
+		holder, ok := s.Holder("otherTree")
+		require.True(t, ok)
+		assert.Equal(t, "otherTree", holder.Tree().Name)
+		rec = do(s, http.MethodPost, "/trees/otherTree/resolve", `10`)
+		assert.JSONEq(t, `{"result":"minor"}`, rec.Body.String())
+	})
+	t.Run("new tree with server functions and comparers", func(t *testing.T) {
+		s.Functions = map[string]function.PreProcessFn{"Double": {Name: "Double", Function: func(v interface{}, args ...interface{}) (interface{}, error) {
+			return v.(int) * 2, nil
+		}}}
+		s.Comparers = map[string]ddt.ComparerFactory{"atLeast": func(data json.RawMessage) (ddt.Comparer, error) {
+			return &compare.Greater{Equal: true}, nil
+		}}
+		rec := do(s, http.MethodPut, "/trees/doubleTree", `{"root": {"preProcessFnName": "Double", "children": [
+			{"comparer": {"type": "atLeast"}, "valueToCompare": {"Value": 20, "Type": "int"}, "result": {"Value": "big", "Type": "string"}},
+			{"default": true, "result": {"Value": "small", "Type": "string"}}
+		]}}`)
+		require.Equal(t, http.StatusOK, rec.Code, rec.Body.String())
+		rec = do(s, http.MethodPost, "/trees/doubleTree/resolve", `10`)
+		assert.JSONEq(t, `{"result":"big"}`, rec.Body.String())
+		rec = do(s, http.MethodPost, "/trees/doubleTree/resolve", `9`)
+		assert.JSONEq(t, `{"result":"small"}`, rec.Body.String())
+	})
+	t.Run("body too large", func(t *testing.T) {
+		s.MaxBodyBytes = 10
+		rec := do(s, http.MethodPut, "/trees/ageTree", ageTree)
+		assert.Equal(t, http.StatusBadRequest, rec.Code)
+	})
+}
+
+func TestNew(t *testing.T) {
+	_, err := New(&ddt.Tree{})
+	assert.EqualError(t, err, "server: tree without name")
+	_, err = New(&ddt.Tree{Name: "invalid"})
+	assert.Error(t, err)
+}
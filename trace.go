@@ -0,0 +1,90 @@
+package ddt
+
+// Trace records the path followed while resolving a tree
+type Trace struct {
+	Steps  []*TraceStep `json:"steps"`
+	Result interface{}  `json:"result"`
+	// DefaultResult is true when the result is the default result of the tree
+	DefaultResult bool `json:"defaultResult,omitempty"`
+}
+
+// TraceStep records the visit of a node, the value produced by its
+// PreProcessFn, Pipeline or Expression and the comparisons made against its children
+type TraceStep struct {
+	NodeID       int           `json:"nodeId"`
+	PreProcessFn string        `json:"preProcessFnName,omitempty"`
+	Pipeline     []string      `json:"pipeline,omitempty"`
+	Expression   string        `json:"expression,omitempty"`
+	Value        interface{}   `json:"value"`
+	Comparisons  []*Comparison `json:"comparisons,omitempty"`
+	// Default is true when no child matched and the default child was taken
+	Default bool `json:"default,omitempty"`
+}
+
+// Comparison records a Comparer.Compare call made against a child node
+type Comparison struct {
+	ChildID        int         `json:"childId"`
+	Comparer       Comparer    `json:"comparer"`
+	Value          interface{} `json:"value"`
+	ValueToCompare interface{} `json:"valueToCompare"`
+	Matched        bool        `json:"matched"`
+}
+
+// VisitedIDs returns the ids of the visited nodes in order
+func (t *Trace) VisitedIDs() []int {
+	ids := make([]int, len(t.Steps))
+	for i, s := range t.Steps {
+		ids[i] = s.NodeID
+	}
+	return ids
+}
+
+// visit adds a step for the node, a nil trace records nothing
+func (t *Trace) visit(n *Node) *TraceStep {
+	if t == nil {
+		return nil
+	}
+	step := &TraceStep{NodeID: n.ID, PreProcessFn: n.PreProcessFn.Name, Pipeline: n.pipelineNames(), Expression: n.Expression}
+	t.Steps = append(t.Steps, step)
+	return step
+}
+
+// visitCompiled adds a step for the compiled node like visit
+func (t *Trace) visitCompiled(n *compiledNode) *TraceStep {
+	if t == nil {
+		return nil
+	}
+	step := &TraceStep{NodeID: n.id, PreProcessFn: n.preProcessFn, Expression: n.expression}
+	if len(n.pipeline) != 0 {
+		step.Pipeline = append([]string(nil), n.pipeline...)
+	}
+	t.Steps = append(t.Steps, step)
+	return step
+}
+
+func (s *TraceStep) setValue(v interface{}) {
+	if s == nil {
+		return
+	}
+	s.Value = v
+}
+
+func (s *TraceStep) tookDefault() {
+	if s == nil {
+		return
+	}
+	s.Default = true
+}
+
+func (s *TraceStep) compared(childID int, c Comparer, v, valueToCompare interface{}, matched bool) {
+	if s == nil {
+		return
+	}
+	s.Comparisons = append(s.Comparisons, &Comparison{
+		ChildID:        childID,
+		Comparer:       c,
+		Value:          v,
+		ValueToCompare: valueToCompare,
+		Matched:        matched,
+	})
+}
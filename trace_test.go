@@ -0,0 +1,53 @@
+package ddt
+
+import (
+	"encoding/json"
+	"errors"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"github.com/sgrodriguez/ddt/compare"
+)
+
+func TestExplainTree(t *testing.T) {
+	ut := userTree()
+	result, trace, err := ExplainTree(ut, newUser(25, "LUCIA", "SANTIAGO"))
+	require.NoError(t, err)
+	assert.Equal(t, "node5", result)
+	assert.Equal(t, "node5", trace.Result)
+	assert.Equal(t, []int{0, 2, 5}, trace.VisitedIDs())
+
+	root := trace.Steps[0]
+	assert.Equal(t, "CallStructMethod", root.PreProcessFn)
+	assert.Equal(t, false, root.Value)
+	require.Len(t, root.Comparisons, 2)
+	assert.Equal(t, &Comparison{ChildID: 1, Comparer: &compare.Equal{}, Value: false, ValueToCompare: true, Matched: false}, root.Comparisons[0])
+	assert.Equal(t, &Comparison{ChildID: 2, Comparer: &compare.Equal{}, Value: false, ValueToCompare: false, Matched: true}, root.Comparisons[1])
+
+	node2 := trace.Steps[1]
+	assert.Equal(t, "GetStructAttribute", node2.PreProcessFn)
+	assert.Equal(t, 25, node2.Value)
+	require.Len(t, node2.Comparisons, 1)
+	assert.True(t, node2.Comparisons[0].Matched)
+	assert.Empty(t, trace.Steps[2].Comparisons)
+
+	b, err := json.Marshal(trace)
+	require.NoError(t, err)
+	assert.Contains(t, string(b), `{"childId":5,"comparer":{"type":"lt","equal":true},"value":25,"valueToCompare":30,"matched":true}`)
+}
+
+func TestExplainTree_Error(t *testing.T) {
+	ut := userTree()
+	result, trace, err := ExplainTree(ut, &user{})
+	assert.True(t, errors.Is(err, ErrNoMatchingChild))
+	assert.Nil(t, result)
+	assert.Equal(t, []int{0, 1}, trace.VisitedIDs())
+	failed := trace.Steps[1]
+	assert.Equal(t, " ", failed.Value)
+	require.Len(t, failed.Comparisons, 2)
+	for _, c := range failed.Comparisons {
+		assert.False(t, c.Matched)
+	}
+}
@@ -0,0 +1,248 @@
+package ddt
+
+import (
+	"fmt"
+	"strings"
+
+	"github.com/sgrodriguez/ddt/compare"
+	"github.com/sgrodriguez/ddt/function"
+	"github.com/sgrodriguez/ddt/value"
+)
+
+// NodeError describes a structural problem found in a node of the tree
+type NodeError struct {
+	NodeID int
+	Msg    string
+}
+
+// Error implements the error interface
+func (e *NodeError) Error() string {
+	return fmt.Sprintf("node %d: %s", e.NodeID, e.Msg)
+}
+
+// ValidationError aggregates every problem found while validating a tree
+type ValidationError struct {
+	Errors []*NodeError
+}
+
+// Error implements the error interface
+func (e *ValidationError) Error() string {
+	msgs := make([]string, len(e.Errors))
+	for i, err := range e.Errors {
+		msgs[i] = err.Error()
+	}
+	return "invalid tree: " + strings.Join(msgs, "; ")
+}
+
+// Validate walks the whole tree and reports every structural problem that
+// would otherwise make the resolution fail or panic. The returned error is a
+// *ValidationError listing the problems annotated with the node ID.
+func (t *Tree) Validate() error {
+	v := &validator{seenIDs: map[int]bool{}, seenNodes: map[*Node]bool{}}
+	if t.Root == nil {
+		v.errors = append(v.errors, &NodeError{NodeID: 0, Msg: "tree has no root node"})
+		return v.err()
+	}
+	if !isValidRootNode(t.Root) {
+		v.addf(t.Root, "invalid root node, it must have id 0, parent id -1 and no result")
+	}
+	v.walk(t.Root)
+	return v.err()
+}
+
+type validator struct {
+	errors    []*NodeError
+	seenIDs   map[int]bool
+	seenNodes map[*Node]bool
+}
+
+func (v *validator) addf(n *Node, format string, args ...interface{}) {
+	v.errors = append(v.errors, &NodeError{NodeID: n.ID, Msg: fmt.Sprintf(format, args...)})
+}
+
+func (v *validator) err() error {
+	if len(v.errors) == 0 {
+		return nil
+	}
+	return &ValidationError{Errors: v.errors}
+}
+
+func (v *validator) walk(root *Node) {
+	v.seenNodes[root] = true
+	v.seenIDs[root.ID] = true
+	if len(root.Children) == 0 && root.Result == nil {
+		v.addf(root, "leaf node without result")
+	}
+	v.checkPreProcessFn(root)
+	queue := []*Node{root}
+	for len(queue) != 0 {
+		top := queue[0]
+		queue = queue[1:]
+		defaults := 0
+		for _, c := range top.Children {
+			if c == nil {
+				v.addf(top, "nil child node")
+				continue
+			}
+			if v.seenNodes[c] {
+				v.addf(c, "node is reachable more than once (cycle or shared node)")
+				continue
+			}
+			v.seenNodes[c] = true
+			v.checkNode(top, c)
+			queue = append(queue, c)
+			if c.Default {
+				defaults++
+			}
+		}
+		if defaults > 1 {
+			v.addf(top, "more than one default child")
+		}
+	}
+}
+
+func (v *validator) checkNode(parent, n *Node) {
+	if v.seenIDs[n.ID] {
+		v.addf(n, "duplicate node id")
+	}
+	v.seenIDs[n.ID] = true
+	if n.ParentID != parent.ID {
+		v.addf(n, "parent id %d does not match actual parent %d", n.ParentID, parent.ID)
+	}
+	if !n.Default {
+		v.checkComparer(n, "", n.Comparer, n.ValueToCompare)
+	}
+	if len(n.Children) == 0 && n.Result == nil {
+		v.addf(n, "leaf node without result")
+	}
+	v.checkPreProcessFn(n)
+}
+
+// checkComparer checks a comparer and its value to compare, the operands of
+// composite comparers are checked with the prefix "operand <index>: "
+func (v *validator) checkComparer(n *Node, prefix string, c Comparer, val *value.Value) {
+	if c == nil {
+		v.addf(n, "%smissing comparer", prefix)
+		if val == nil {
+			v.addf(n, "%smissing value to compare", prefix)
+		}
+		return
+	}
+	switch comp := c.(type) {
+	case *compare.And:
+		v.checkOperands(n, prefix, "and", comp.Operands)
+		return
+	case *compare.Or:
+		v.checkOperands(n, prefix, "or", comp.Operands)
+		return
+	case *compare.Not:
+		if comp.Operand == nil {
+			v.addf(n, "%snot comparer without operand", prefix)
+			return
+		}
+		v.checkComparer(n, prefix+"operand: ", comp.Operand.Comparer, comp.Operand.Value)
+		return
+	}
+	if val == nil {
+		v.addf(n, "%smissing value to compare", prefix)
+		return
+	}
+	switch comp := c.(type) {
+	case *compare.Regex:
+		v.checkPattern(n, prefix, comp, val)
+	case *compare.In, *compare.NotIn:
+		v.checkList(n, prefix, c, val)
+	case *compare.Between:
+		v.checkBounds(n, prefix, val)
+	}
+}
+
+func (v *validator) checkOperands(n *Node, prefix, comp string, operands []*compare.Operand) {
+	if len(operands) == 0 {
+		v.addf(n, "%s%s comparer without operands", prefix, comp)
+	}
+	for i, op := range operands {
+		opPrefix := fmt.Sprintf("%soperand %d: ", prefix, i)
+		if op == nil {
+			v.addf(n, "%smissing comparer", opPrefix)
+			continue
+		}
+		v.checkComparer(n, opPrefix, op.Comparer, op.Value)
+	}
+}
+
+func (v *validator) checkList(n *Node, prefix string, c Comparer, val *value.Value) {
+	if _, ok := val.Value.([]interface{}); val.Type != value.List || !ok {
+		v.addf(n, "%s%s comparer needs a list value to compare", prefix, comparerType(c))
+	}
+}
+
+func (v *validator) checkBounds(n *Node, prefix string, val *value.Value) {
+	bounds, ok := val.Value.([]interface{})
+	if val.Type != value.List || !ok || len(bounds) != 2 {
+		v.addf(n, "%sbetween comparer needs a list value to compare with the lower and upper bounds", prefix)
+		return
+	}
+	lowerType, _ := value.TypeOf(bounds[0])
+	upperType, _ := value.TypeOf(bounds[1])
+	if lowerType != upperType || lowerType == value.Bool {
+		v.addf(n, "%sbetween bounds must be numbers or strings of the same type", prefix)
+		return
+	}
+	if (&compare.Greater{}).Compare(bounds[0], bounds[1]) {
+		v.addf(n, "%sbetween lower bound %v is greater than upper bound %v", prefix, bounds[0], bounds[1])
+	}
+}
+
+func (v *validator) checkPattern(n *Node, prefix string, r *compare.Regex, val *value.Value) {
+	pattern, ok := val.Value.(string)
+	if !ok {
+		v.addf(n, "%sregex comparer needs a string value to compare", prefix)
+		return
+	}
+	if _, err := r.Regexp(pattern); err != nil {
+		v.addf(n, "%sinvalid regex: %v", prefix, err)
+	}
+}
+
+func (v *validator) checkPreProcessFn(n *Node) {
+	if n.Expression != "" {
+		if !n.PreProcessFn.Empty() || len(n.Pipeline) != 0 {
+			v.addf(n, "expression and pre process function or pipeline are mutually exclusive")
+		}
+		if _, err := n.expression(); err != nil {
+			v.addf(n, "invalid expression: %v", err)
+		}
+	}
+	if !n.PreProcessFn.Empty() && !n.PreProcessFn.Defined() {
+		v.addf(n, "pre process function %q has no implementation", n.PreProcessFn.Name)
+	}
+	v.checkArgs(n, "", n.PreProcessFn, n.PreProcessArgs)
+	if len(n.Pipeline) != 0 && !n.PreProcessFn.Empty() {
+		v.addf(n, "pre process function and pipeline are mutually exclusive")
+	}
+	for i, s := range n.Pipeline {
+		switch {
+		case s == nil || s.PreProcessFn.Empty():
+			v.addf(n, "pipeline step %d has no pre process function", i)
+		case !s.PreProcessFn.Defined():
+			v.addf(n, "pipeline step %d: pre process function %q has no implementation", i, s.PreProcessFn.Name)
+		default:
+			v.checkArgs(n, fmt.Sprintf("pipeline step %d: ", i), s.PreProcessFn, s.PreProcessArgs)
+		}
+	}
+}
+
+// checkArgs checks the args of a pre process function, the functions of the
+// standard library validate them so invalid args are found before resolving
+func (v *validator) checkArgs(n *Node, prefix string, fn function.PreProcessFn, args []*value.Value) {
+	for i, a := range args {
+		if a == nil {
+			v.addf(n, "%spre process function %q arg %d is nil", prefix, fn.Name, i)
+			return
+		}
+	}
+	if err := function.ValidateArgs(fn, value.GetValueInterfaces(args)...); err != nil {
+		v.addf(n, "%spre process function %q: %v", prefix, fn.Name, err)
+	}
+}
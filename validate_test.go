@@ -0,0 +1,232 @@
+package ddt
+
+import (
+	"encoding/json"
+	"errors"
+	"fmt"
+	"sync"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"github.com/sgrodriguez/ddt/compare"
+	"github.com/sgrodriguez/ddt/function"
+	"github.com/sgrodriguez/ddt/value"
+)
+
+func TestValidate_ValidTrees(t *testing.T) {
+	require.NoError(t, userTree().Validate())
+	leafRoot := &Node{ID: 0, ParentID: -1, Children: []*Node{{ID: 1, ParentID: 0, Default: true, Result: &value.Value{Type: value.Int, Value: 1}}}}
+	_, err := NewTree("defaultOnly", leafRoot)
+	require.NoError(t, err)
+	// a nil root creates an empty tree to decode into, it is not valid
+	emptyTree, err := NewTree("emptyTree", nil)
+	require.NoError(t, err)
+	assert.Error(t, emptyTree.Validate())
+}
+
+func TestValidate_InvalidTrees(t *testing.T) {
+	testCases := map[string]struct {
+		root     func() *Node
+		expected []string
+	}{
+		"root without children": {
+			root: func() *Node {
+				return &Node{ID: 0, ParentID: -1}
+			},
+			expected: []string{"node 0: leaf node without result"},
+		},
+		"leaf without result": {
+			root: func() *Node {
+				leaf := &Node{ID: 1, ParentID: 0, Comparer: &compare.Equal{}, ValueToCompare: &value.Value{Type: value.Int, Value: 1}}
+				return &Node{ID: 0, ParentID: -1, Children: []*Node{leaf}}
+			},
+			expected: []string{"node 1: leaf node without result"},
+		},
+		"child without comparer and value to compare": {
+			root: func() *Node {
+				leaf := &Node{ID: 1, ParentID: 0, Result: &value.Value{Type: value.Int, Value: 1}}
+				return &Node{ID: 0, ParentID: -1, Children: []*Node{leaf}}
+			},
+			expected: []string{"node 1: missing comparer", "node 1: missing value to compare"},
+		},
+		"duplicate ids and wrong parent id": {
+			root: func() *Node {
+				leaf1 := &Node{ID: 1, ParentID: 0, Comparer: &compare.Equal{}, ValueToCompare: &value.Value{Type: value.Int, Value: 1}, Result: &value.Value{Type: value.Int, Value: 1}}
+				leaf2 := &Node{ID: 1, ParentID: 3, Comparer: &compare.Equal{}, ValueToCompare: &value.Value{Type: value.Int, Value: 2}, Result: &value.Value{Type: value.Int, Value: 2}}
+				return &Node{ID: 0, ParentID: -1, Children: []*Node{leaf1, leaf2}}
+			},
+			expected: []string{"node 1: duplicate node id", "node 1: parent id 3 does not match actual parent 0"},
+		},
+		"pre process function without implementation": {
+			root: func() *Node {
+				leaf := &Node{ID: 1, ParentID: 0, Comparer: &compare.Equal{}, ValueToCompare: &value.Value{Type: value.Int, Value: 1}, Result: &value.Value{Type: value.Int, Value: 1}}
+				return &Node{ID: 0, ParentID: -1, Children: []*Node{leaf}, PreProcessFn: function.PreProcessFn{Name: "Missing"}}
+			},
+			expected: []string{`node 0: pre process function "Missing" has no implementation`},
+		},
+		"pre process function args": {
+			root: func() *Node {
+				str := func(s string) *value.Value { return &value.Value{Type: value.String, Value: s} }
+				fn := func(name string, f func(interface{}, ...interface{}) (interface{}, error)) function.PreProcessFn {
+					return function.PreProcessFn{Name: name, Function: f}
+				}
+				leaf := &Node{ID: 2, ParentID: 1, Default: true, Result: &value.Value{Type: value.Int, Value: 2}}
+				return &Node{ID: 0, ParentID: -1, PreProcessFn: fn("Substring", function.Substring), PreProcessArgs: []*value.Value{str("a")},
+					Children: []*Node{
+						{ID: 1, ParentID: 0, Default: true, Pipeline: []*PipelineStep{
+							{PreProcessFn: fn("Lower", function.Lower)},
+							{PreProcessFn: fn("Hour", function.Hour), PreProcessArgs: []*value.Value{str("Nowhere/City")}},
+							{PreProcessFn: fn("Split", function.Split), PreProcessArgs: []*value.Value{nil}},
+						}, Children: []*Node{leaf}},
+					}}
+			},
+			expected: []string{
+				`node 0: pre process function "Substring": substring invalid args: arg 0 must be an int, got a (string)`,
+				`node 1: pipeline step 1: pre process function "Hour": hour invalid args: unknown time zone Nowhere/City`,
+				`node 1: pipeline step 2: pre process function "Split" arg 0 is nil`,
+			},
+		},
+		"invalid regex patterns": {
+			root: func() *Node {
+				leaf1 := &Node{ID: 1, ParentID: 0, Comparer: &compare.Regex{}, ValueToCompare: &value.Value{Type: value.String, Value: "a("}, Result: &value.Value{Type: value.Int, Value: 1}}
+				leaf2 := &Node{ID: 2, ParentID: 0, Comparer: &compare.Regex{}, ValueToCompare: &value.Value{Type: value.Int, Value: 2}, Result: &value.Value{Type: value.Int, Value: 2}}
+				return &Node{ID: 0, ParentID: -1, Children: []*Node{leaf1, leaf2}}
+			},
+			expected: []string{"node 1: invalid regex: error parsing regexp: missing closing ): `a(`", "node 2: regex comparer needs a string value to compare"},
+		},
+		"set comparers without list": {
+			root: func() *Node {
+				leaf1 := &Node{ID: 1, ParentID: 0, Comparer: &compare.In{}, ValueToCompare: &value.Value{Type: value.String, Value: "UY"}, Result: &value.Value{Type: value.Int, Value: 1}}
+				leaf2 := &Node{ID: 2, ParentID: 0, Comparer: &compare.NotIn{}, ValueToCompare: &value.Value{Type: value.Int, Value: 2}, Result: &value.Value{Type: value.Int, Value: 2}}
+				return &Node{ID: 0, ParentID: -1, Children: []*Node{leaf1, leaf2}}
+			},
+			expected: []string{"node 1: in comparer needs a list value to compare", "node 2: notIn comparer needs a list value to compare"},
+		},
+		"between comparers with invalid bounds": {
+			root: func() *Node {
+				list := func(bounds ...interface{}) *value.Value { return &value.Value{Type: value.List, Value: bounds} }
+				leaf := func(id int, v *value.Value) *Node {
+					return &Node{ID: id, ParentID: 0, Comparer: &compare.Between{}, ValueToCompare: v, Result: &value.Value{Type: value.Int, Value: id}}
+				}
+				return &Node{ID: 0, ParentID: -1, Children: []*Node{
+					leaf(1, &value.Value{Type: value.Int, Value: 1}),
+					leaf(2, list(1, 2, 3)),
+					leaf(3, list(1, int64(2))),
+					leaf(4, list(false, true)),
+					leaf(5, list(60, 30)),
+					leaf(6, list(30, 60)),
+				}}
+			},
+			expected: []string{
+				"node 1: between comparer needs a list value to compare with the lower and upper bounds",
+				"node 2: between comparer needs a list value to compare with the lower and upper bounds",
+				"node 3: between bounds must be numbers or strings of the same type",
+				"node 4: between bounds must be numbers or strings of the same type",
+				"node 5: between lower bound 60 is greater than upper bound 30",
+			},
+		},
+		"composite comparers": {
+			root: func() *Node {
+				leaf := func(id int, c Comparer) *Node {
+					return &Node{ID: id, ParentID: 0, Comparer: c, Result: &value.Value{Type: value.Int, Value: id}}
+				}
+				return &Node{ID: 0, ParentID: -1, Children: []*Node{
+					leaf(1, &compare.And{Operands: []*compare.Operand{{Comparer: &compare.Equal{}, Value: &value.Value{Type: value.Int, Value: 1}}}}),
+					leaf(2, &compare.And{}),
+					leaf(3, &compare.Or{Operands: []*compare.Operand{nil, {Comparer: &compare.Equal{}}, {Value: &value.Value{Type: value.Int, Value: 1}}}}),
+					leaf(4, &compare.Not{}),
+					leaf(5, &compare.Not{Operand: &compare.Operand{Comparer: &compare.Or{Operands: []*compare.Operand{
+						{Comparer: &compare.Regex{}, Value: &value.Value{Type: value.String, Value: "("}},
+					}}}}),
+				}}
+			},
+			expected: []string{
+				"node 2: and comparer without operands",
+				"node 3: operand 0: missing comparer",
+				"node 3: operand 1: missing value to compare",
+				"node 3: operand 2: missing comparer",
+				"node 4: not comparer without operand",
+				"node 5: operand: operand 0: invalid regex: error parsing regexp: missing closing ): `(`",
+			},
+		},
+		"cyclic children": {
+			root: func() *Node {
+				inner := &Node{ID: 1, ParentID: 0, Comparer: &compare.Equal{}, ValueToCompare: &value.Value{Type: value.Int, Value: 1}}
+				root := &Node{ID: 0, ParentID: -1, Children: []*Node{inner}}
+				inner.Children = []*Node{root}
+				return root
+			},
+			expected: []string{"node 0: node is reachable more than once (cycle or shared node)"},
+		},
+	}
+	for name, tc := range testCases {
+		t.Run(name, func(t *testing.T) {
+			_, err := NewTree(name, tc.root())
+			require.Error(t, err)
+			var validationErr *ValidationError
+			require.True(t, errors.As(err, &validationErr))
+			var msgs []string
+			for _, e := range validationErr.Errors {
+				msgs = append(msgs, e.Error())
+			}
+			assert.Equal(t, tc.expected, msgs)
+		})
+	}
+	t.Run("nil root", func(t *testing.T) {
+		err := (&Tree{}).Validate()
+		assert.EqualError(t, err, "invalid tree: node 0: tree has no root node")
+	})
+}
+
+func TestValidate_UnmarshalJSON(t *testing.T) {
+	ut := userTree()
+	// node 4 is a leaf without result
+	invalidTree := []byte(`{"nodes":[{"preProcessFnName":"","id":0,"parentId":-1},{"preProcessFnName":"","id":4,"parentId":0,"comparer":{"type":"eq"},"valueToCompare":{"Value":30,"Type":"int"}}],"name":"invalidTree"}`)
+	err := json.Unmarshal(invalidTree, ut)
+	assert.EqualError(t, err, "invalid tree: node 4: leaf node without result")
+	// the previous tree is kept
+	assert.Equal(t, "userTree", ut.Name)
+	result, err := ResolveTree(ut, newUser(11, "LUCIA", "SANTIAGO"))
+	require.NoError(t, err)
+	assert.Equal(t, "node4", result)
+}
+
+func TestValidate_WhileResolving(t *testing.T) {
+	countries := make([]interface{}, 20)
+	for i := range countries {
+		countries[i] = fmt.Sprintf("C%d", i)
+	}
+	str := func(s string) *value.Value { return &value.Value{Type: value.String, Value: s} }
+	root := &Node{ID: 0, ParentID: -1, Children: []*Node{
+		{ID: 1, ParentID: 0, Comparer: &compare.In{}, ValueToCompare: &value.Value{Type: value.List, Value: countries}, Result: str("in")},
+		{ID: 2, ParentID: 0, Comparer: &compare.Regex{}, ValueToCompare: str(`^X\d+$`), Result: str("x")},
+		{ID: 3, ParentID: 0, Default: true, Result: str("out")},
+	}}
+	tree, err := NewTree("validating", root)
+	require.NoError(t, err)
+	var wg sync.WaitGroup
+	for i := 0; i < 4; i++ {
+		wg.Add(2)
+		go func() {
+			defer wg.Done()
+			assert.NoError(t, tree.Validate())
+		}()
+		go func() {
+			defer wg.Done()
+			res, err := ResolveTree(tree, "C7")
+			assert.NoError(t, err)
+			assert.Equal(t, "in", res)
+			res, err = ResolveTree(tree, "X7")
+			assert.NoError(t, err)
+			assert.Equal(t, "x", res)
+		}()
+	}
+	wg.Wait()
+
+	countries[3] = "C100"
+	res, err := ResolveTree(tree, "C100")
+	require.NoError(t, err)
+	assert.Equal(t, "in", res, "lists modified in place are seen")
+}
@@ -0,0 +1,83 @@
+package value
+
+import (
+	"bytes"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"math"
+)
+
+// TypeOf returns the type of a bool, int, int64, float64 or string value
+func TypeOf(v interface{}) (Type, error) {
+	switch v.(type) {
+	case bool:
+		return Bool, nil
+	case int:
+		return Int, nil
+	case int64:
+		return Int64, nil
+	case float64:
+		return Float64, nil
+	case string:
+		return String, nil
+	}
+	return "", fmt.Errorf("invalid list element %v (%T)", v, v)
+}
+
+// inferType is the type given to a plain json value of a list
+func inferType(v interface{}) Type {
+	switch e := v.(type) {
+	case float64:
+		if e == math.Trunc(e) && !math.IsInf(e, 0) {
+			return ""
+		}
+		return Float64
+	case int64:
+		return ""
+	}
+	t, _ := TypeOf(v)
+	return t
+}
+
+func unmarshalList(data []byte) ([]interface{}, error) {
+	var raws []json.RawMessage
+	if err := json.Unmarshal(data, &raws); err != nil {
+		return nil, err
+	}
+	list := make([]interface{}, len(raws))
+	for i, raw := range raws {
+		if bytes.HasPrefix(bytes.TrimSpace(raw), []byte("{")) {
+			var typed Value
+			if err := json.Unmarshal(raw, &typed); err != nil {
+				return nil, err
+			}
+			if typed.Type == List {
+				return nil, errors.New("nested list value")
+			}
+			list[i] = typed.Value
+			continue
+		}
+		var e interface{}
+		dec := json.NewDecoder(bytes.NewReader(raw))
+		dec.UseNumber()
+		if err := dec.Decode(&e); err != nil {
+			return nil, err
+		}
+		switch elem := e.(type) {
+		case json.Number:
+			if n, err := elem.Int64(); err == nil && int64(int(n)) == n {
+				list[i] = int(n)
+			} else if f, err := elem.Float64(); err == nil {
+				list[i] = f
+			} else {
+				return nil, err
+			}
+		case bool, string:
+			list[i] = elem
+		default:
+			return nil, fmt.Errorf("invalid list element %s", raw)
+		}
+	}
+	return list, nil
+}
@@ -0,0 +1,60 @@
+package value
+
+import (
+	"encoding/json"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestListValue(t *testing.T) {
+	tests := map[string]struct {
+		list     []interface{}
+		expected string
+	}{
+		"strings":       {list: []interface{}{"UY", "AR"}, expected: `{"Value":["UY","AR"],"Type":"list"}`},
+		"ints":          {list: []interface{}{1, 2}, expected: `{"Value":[1,2],"Type":"list"}`},
+		"typed numbers": {list: []interface{}{int64(1), 2.0, 2.5}, expected: `{"Value":[{"Value":1,"Type":"int64"},{"Value":2,"Type":"float64"},2.5],"Type":"list"}`},
+		"mixed":         {list: []interface{}{true, "a", 1}, expected: `{"Value":[true,"a",1],"Type":"list"}`},
+		"empty":         {list: []interface{}{}, expected: `{"Value":[],"Type":"list"}`},
+	}
+	for name, tc := range tests {
+		t.Run(name, func(t *testing.T) {
+			v, err := NewValue(List, tc.list)
+			require.NoError(t, err)
+			got, err := json.Marshal(v)
+			require.NoError(t, err)
+			assert.JSONEq(t, tc.expected, string(got))
+			var decoded Value
+			require.NoError(t, json.Unmarshal(got, &decoded))
+			assert.Equal(t, *v, decoded)
+		})
+	}
+	t.Run("typed elements", func(t *testing.T) {
+		var v Value
+		require.NoError(t, json.Unmarshal([]byte(`{"Value":[{"Value":"x","Type":"string"},{"Value":3,"Type":"int64"}],"Type":"list"}`), &v))
+		assert.Equal(t, Value{Type: List, Value: []interface{}{"x", int64(3)}}, v)
+	})
+	testErrors := map[string]string{
+		"not array":      `{"Value":"UY","Type":"list"}`,
+		"nested list":    `{"Value":[{"Value":[1],"Type":"list"}],"Type":"list"}`,
+		"object element": `{"Value":[{"a":1}],"Type":"list"}`,
+		"array element":  `{"Value":[[1]],"Type":"list"}`,
+		"null element":   `{"Value":[null],"Type":"list"}`,
+	}
+	for name, input := range testErrors {
+		t.Run(name, func(t *testing.T) {
+			var v Value
+			assert.Error(t, json.Unmarshal([]byte(input), &v))
+		})
+	}
+	t.Run("invalid new list", func(t *testing.T) {
+		_, err := NewValue(List, []string{"a"})
+		assert.Error(t, err)
+		_, err = NewValue(List, []interface{}{[]int{1}})
+		assert.Error(t, err)
+		_, err = json.Marshal(&Value{Type: List, Value: []interface{}{uint(1)}})
+		assert.Error(t, err)
+	})
+}
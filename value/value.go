@@ -25,6 +25,8 @@ const (
 	Float64 = "float64"
 	// String type
 	String = "string"
+	// List type, a []interface{} of bool, int, int64, float64 or string values
+	List = "list"
 )
 
 // NewValue creates a valid value
@@ -50,6 +52,16 @@ func NewValue(t Type, val interface{}) (*Value, error) {
 		if _, ok := val.(float64); !ok {
 			return nil, errors.New("invalid float64 value")
 		}
+	case List:
+		list, ok := val.([]interface{})
+		if !ok {
+			return nil, errors.New("invalid list value")
+		}
+		for _, e := range list {
+			if _, err := TypeOf(e); err != nil {
+				return nil, err
+			}
+		}
 	}
 	return &Value{
 		Type:  t,
@@ -57,6 +69,34 @@ func NewValue(t Type, val interface{}) (*Value, error) {
 	}, nil
 }
 
+// MarshalJSON encodes the list values as an array, the elements are written
+// as plain json values when their type is the one inferred when decoding them
+// (bool, string, int and float64 with decimals) and as typed values otherwise.
+// Any other value is encoded like a struct.
+func (v Value) MarshalJSON() ([]byte, error) {
+	type valueAlias Value
+	if v.Type != List {
+		return json.Marshal(valueAlias(v))
+	}
+	list, ok := v.Value.([]interface{})
+	if !ok {
+		return nil, errors.New("invalid list value")
+	}
+	elems := make([]interface{}, len(list))
+	for i, e := range list {
+		t, err := TypeOf(e)
+		if err != nil {
+			return nil, err
+		}
+		if t == inferType(e) {
+			elems[i] = e
+			continue
+		}
+		elems[i] = valueAlias{Value: e, Type: t}
+	}
+	return json.Marshal(valueAlias{Value: elems, Type: List})
+}
+
 // UnmarshalJSON ...
 func (v *Value) UnmarshalJSON(data []byte) error {
 	val := struct {
@@ -103,6 +143,13 @@ func (v *Value) UnmarshalJSON(data []byte) error {
 		}
 		v.Value = s
 		return nil
+	case List:
+		list, err := unmarshalList(val.Value)
+		if err != nil {
+			return err
+		}
+		v.Value = list
+		return nil
 	}
 	return errors.New("unmarshal failed invalid value type")
 }
@@ -0,0 +1,106 @@
+package ddt
+
+import (
+	"bytes"
+	"context"
+	"crypto/sha256"
+	"io/ioutil"
+	"path/filepath"
+	"sync"
+	"time"
+)
+
+// DefaultWatchInterval is the polling interval of the watchers created with a
+// non positive interval
+const DefaultWatchInterval = time.Second
+
+// FileWatcher polls a json (or yaml, by the .yaml or .yml extension) tree
+// file, loading every new version of it into a Holder. When a version fails
+// to decode or validate the holder keeps the previous one. OnReload and
+// OnError, when defined, are called after each reload attempt. Check can be
+// called while Run is running, the checks are serialized.
+type FileWatcher struct {
+	Path   string
+	Holder *Holder
+	// Interval is the polling interval of Run, DefaultWatchInterval when it
+	// is not positive
+	Interval time.Duration
+	OnReload func(t *Tree)
+	OnError  func(err error)
+
+	mu sync.Mutex
+	// lastSum is the checksum of the last content loaded or rejected
+	lastSum []byte
+}
+
+// NewFileWatcher creates a watcher for the file polled every interval, or
+// every DefaultWatchInterval when it is not positive. When the holder already
+// has a tree it is taken as loaded from the current content of the file,
+// which is not reloaded until it changes, otherwise the first check loads it.
+func NewFileWatcher(path string, holder *Holder, interval time.Duration) *FileWatcher {
+	if interval <= 0 {
+		interval = DefaultWatchInterval
+	}
+	w := &FileWatcher{Path: path, Holder: holder, Interval: interval}
+	if holder.Tree() != nil {
+		if data, err := ioutil.ReadFile(path); err == nil {
+			sum := sha256.Sum256(data)
+			w.lastSum = sum[:]
+		}
+	}
+	return w
+}
+
+// Check reloads the file when its content changed since the last check,
+// reporting whether a new version was loaded
+func (w *FileWatcher) Check() (bool, error) {
+	w.mu.Lock()
+	defer w.mu.Unlock()
+	data, err := ioutil.ReadFile(w.Path)
+	if err != nil {
+		w.failed(err)
+		return false, err
+	}
+	sum := sha256.Sum256(data)
+	if bytes.Equal(sum[:], w.lastSum) {
+		return false, nil
+	}
+	// a rejected content is not retried until the file changes again
+	w.lastSum = sum[:]
+	load := w.Holder.LoadJSON
+	if ext := filepath.Ext(w.Path); ext == ".yaml" || ext == ".yml" {
+		load = w.Holder.LoadYAML
+	}
+	if err := load(data); err != nil {
+		w.failed(err)
+		return false, err
+	}
+	if w.OnReload != nil {
+		w.OnReload(w.Holder.Tree())
+	}
+	return true, nil
+}
+
+// Run checks the file every Interval until the context is done
+func (w *FileWatcher) Run(ctx context.Context) {
+	interval := w.Interval
+	if interval <= 0 {
+		interval = DefaultWatchInterval
+	}
+	ticker := time.NewTicker(interval)
+	defer ticker.Stop()
+	for {
+		_, _ = w.Check()
+		select {
+		case <-ctx.Done():
+			return
+		case <-ticker.C:
+		}
+	}
+}
+
+func (w *FileWatcher) failed(err error) {
+	if w.OnError != nil {
+		w.OnError(err)
+	}
+}
@@ -0,0 +1,142 @@
+package ddt
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"io/ioutil"
+	"os"
+	"path/filepath"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestFileWatcher_Check(t *testing.T) {
+	holder, err := NewHolder(userTree())
+	require.NoError(t, err)
+	b, err := json.Marshal(holder.Tree())
+	require.NoError(t, err)
+	path := filepath.Join(t.TempDir(), "tree.json")
+
+	var reloaded []string
+	var failures []error
+	watcher := NewFileWatcher(path, holder, time.Millisecond)
+	watcher.OnReload = func(tree *Tree) {
+		reloaded = append(reloaded, tree.Name)
+	}
+	watcher.OnError = func(err error) {
+		failures = append(failures, err)
+	}
+
+	_, err = watcher.Check()
+	assert.True(t, os.IsNotExist(err))
+
+	require.NoError(t, ioutil.WriteFile(path, modifyNode4Result(t, b, "v1"), 0600))
+	changed, err := watcher.Check()
+	require.NoError(t, err)
+	assert.True(t, changed)
+	assertNode4Result(t, holder, "v1")
+
+	changed, err = watcher.Check()
+	require.NoError(t, err)
+	assert.False(t, changed, "same content is not reloaded")
+
+	require.NoError(t, ioutil.WriteFile(path, []byte(`{"name":"broken","nodes":[`), 0600))
+	changed, err = watcher.Check()
+	assert.Error(t, err)
+	assert.False(t, changed)
+	assertNode4Result(t, holder, "v1")
+	changed, err = watcher.Check()
+	assert.NoError(t, err, "rejected content is not retried")
+	assert.False(t, changed)
+
+	require.NoError(t, ioutil.WriteFile(path, []byte(`{"name":"orphans","nodes":[{"id":0,"parentId":-1},{"id":2,"parentId":1}]}`), 0600))
+	_, err = watcher.Check()
+	assert.True(t, errors.Is(err, ErrOrphanNodes))
+	assertNode4Result(t, holder, "v1")
+
+	require.NoError(t, ioutil.WriteFile(path, modifyNode4Result(t, b, "v2"), 0600))
+	changed, err = watcher.Check()
+	require.NoError(t, err)
+	assert.True(t, changed)
+	assertNode4Result(t, holder, "v2")
+
+	assert.Equal(t, []string{"userTree", "userTree"}, reloaded)
+	assert.Len(t, failures, 3)
+}
+
+func TestFileWatcher_Run(t *testing.T) {
+	b, err := json.Marshal(userTree())
+	require.NoError(t, err)
+	holder := &Holder{}
+	path := filepath.Join(t.TempDir(), "tree.json")
+	require.NoError(t, ioutil.WriteFile(path, modifyNode4Result(t, b, "v1"), 0600))
+
+	reloads := make(chan struct{}, 10)
+	watcher := NewFileWatcher(path, holder, time.Millisecond)
+	watcher.OnReload = func(*Tree) {
+		reloads <- struct{}{}
+	}
+	ctx, cancel := context.WithCancel(context.Background())
+	stopped := make(chan struct{})
+	go func() {
+		watcher.Run(ctx)
+		close(stopped)
+	}()
+	<-reloads
+	assertNode4Result(t, holder, "v1")
+	require.NoError(t, ioutil.WriteFile(path, modifyNode4Result(t, b, "v2"), 0600))
+	<-reloads
+	assertNode4Result(t, holder, "v2")
+	changed, err := watcher.Check()
+	require.NoError(t, err)
+	assert.False(t, changed, "checks are serialized with Run")
+	cancel()
+	<-stopped
+
+	t.Run("non positive interval", func(t *testing.T) {
+		assert.Equal(t, DefaultWatchInterval, NewFileWatcher(path, holder, 0).Interval)
+		ctx, cancel := context.WithCancel(context.Background())
+		cancel()
+		watcher := &FileWatcher{Path: path, Holder: holder, Interval: -time.Second}
+		watcher.Run(ctx)
+	})
+}
+
+func assertNode4Result(t *testing.T, holder *Holder, expected string) {
+	result, err := holder.Resolve(newUser(11, "LUCIA", "SANTIAGO"))
+	require.NoError(t, err)
+	assert.Equal(t, expected, result)
+}
+
+func TestFileWatcher_YAML(t *testing.T) {
+	holder := &Holder{}
+	path := filepath.Join(t.TempDir(), "tree.yaml")
+	require.NoError(t, ioutil.WriteFile(path, []byte("root:\n  children:\n    - {default: true, result: {Value: yaml, Type: string}}\n"), 0600))
+	changed, err := NewFileWatcher(path, holder, time.Second).Check()
+	require.NoError(t, err)
+	assert.True(t, changed)
+	result, err := holder.Resolve(nil)
+	require.NoError(t, err)
+	assert.Equal(t, "yaml", result)
+}
+
+func TestFileWatcher_InitialLoad(t *testing.T) {
+	b, err := json.Marshal(userTree())
+	require.NoError(t, err)
+	path := filepath.Join(t.TempDir(), "tree.json")
+	require.NoError(t, ioutil.WriteFile(path, b, 0600))
+	holder := &Holder{}
+	require.NoError(t, holder.LoadJSON(b))
+
+	watcher := NewFileWatcher(path, holder, time.Second)
+	watcher.OnReload = func(*Tree) {
+		t.Error("unchanged file reloaded")
+	}
+	changed, err := watcher.Check()
+	require.NoError(t, err)
+	assert.False(t, changed)
+}
@@ -0,0 +1,101 @@
+package ddt
+
+import (
+	"bytes"
+	"encoding/json"
+	"fmt"
+
+	"gopkg.in/yaml.v3"
+
+	"github.com/sgrodriguez/ddt/function"
+)
+
+// MarshalYAML encodes the tree in the flat nodes representation, with the
+// same fields of the json representation
+func (t *Tree) MarshalYAML() (interface{}, error) {
+	b, err := json.Marshal(t)
+	if err != nil {
+		return nil, err
+	}
+	return jsonToYAMLValue(b)
+}
+
+// MarshalNestedYAML encodes the tree in the nested representation like MarshalNestedJSON
+func (t *Tree) MarshalNestedYAML() ([]byte, error) {
+	b, err := t.MarshalNestedJSON()
+	if err != nil {
+		return nil, err
+	}
+	v, err := jsonToYAMLValue(b)
+	if err != nil {
+		return nil, err
+	}
+	return yaml.Marshal(v)
+}
+
+// UnmarshalYAML decodes the flat or nested representation like UnmarshalJSON,
+// with the same validation and errors
+func (t *Tree) UnmarshalYAML(node *yaml.Node) error {
+	var v interface{}
+	if err := node.Decode(&v); err != nil {
+		return err
+	}
+	jsonValue, err := yamlToJSONValue(v)
+	if err != nil {
+		return err
+	}
+	b, err := json.Marshal(jsonValue)
+	if err != nil {
+		return err
+	}
+	return t.UnmarshalJSON(b)
+}
+
+// jsonToYAMLValue decodes json normalizing the numbers like
+// function.NormalizeJSON, so integers are not encoded as floats or strings
+func jsonToYAMLValue(data []byte) (interface{}, error) {
+	dec := json.NewDecoder(bytes.NewReader(data))
+	dec.UseNumber()
+	var v interface{}
+	if err := dec.Decode(&v); err != nil {
+		return nil, err
+	}
+	return function.NormalizeJSON(v), nil
+}
+
+// yamlToJSONValue converts the maps with non string keys decoded from yaml
+func yamlToJSONValue(v interface{}) (interface{}, error) {
+	switch val := v.(type) {
+	case map[interface{}]interface{}:
+		res := make(map[string]interface{}, len(val))
+		for k, e := range val {
+			key, ok := k.(string)
+			if !ok {
+				return nil, fmt.Errorf("invalid yaml key %v, keys must be strings", k)
+			}
+			converted, err := yamlToJSONValue(e)
+			if err != nil {
+				return nil, err
+			}
+			res[key] = converted
+		}
+		return res, nil
+	case map[string]interface{}:
+		for k, e := range val {
+			converted, err := yamlToJSONValue(e)
+			if err != nil {
+				return nil, err
+			}
+			val[k] = converted
+		}
+	case []interface{}:
+		for i, e := range val {
+			converted, err := yamlToJSONValue(e)
+			if err != nil {
+				return nil, err
+			}
+			val[i] = converted
+		}
+	}
+	return v, nil
+}
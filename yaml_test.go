@@ -0,0 +1,129 @@
+package ddt
+
+import (
+	"encoding/json"
+	"errors"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"gopkg.in/yaml.v3"
+)
+
+func TestYAML_RoundTrip(t *testing.T) {
+	ut := userTree()
+	flatYAML, err := yaml.Marshal(ut)
+	require.NoError(t, err)
+	assert.Contains(t, string(flatYAML), "nodes:")
+	nestedYAML, err := ut.MarshalNestedYAML()
+	require.NoError(t, err)
+	assert.Contains(t, string(nestedYAML), "root:")
+
+	expected, err := json.Marshal(ut)
+	require.NoError(t, err)
+	for name, b := range map[string][]byte{"flat": flatYAML, "nested": nestedYAML} {
+		t.Run(name, func(t *testing.T) {
+			var decoded Tree
+			require.NoError(t, yaml.Unmarshal(b, &decoded))
+			actual, err := json.Marshal(&decoded)
+			require.NoError(t, err)
+			assert.JSONEq(t, string(expected), string(actual))
+		})
+	}
+}
+
+func TestYAML_Decode(t *testing.T) {
+	treeYAML := []byte(`
+name: simpleTree
+defaultResult: {Value: 0, Type: int64}
+root:
+  children:
+    - comparer: {type: gt, equal: false}
+      valueToCompare: {Value: 60, Type: int64}
+      result: {Value: prize1, Type: string}
+    - comparer: {type: lt, equal: true}
+      valueToCompare: {Value: 60, Type: int64}
+      children:
+        - comparer: {type: eq}
+          valueToCompare: {Value: 30, Type: int64}
+          result: {Value: 4.2, Type: float64}
+        - comparer: {type: lt, equal: false}
+          valueToCompare: {Value: 30, Type: int64}
+          result: {Value: true, Type: bool}
+`)
+	var tree Tree
+	require.NoError(t, yaml.Unmarshal(treeYAML, &tree))
+	testCases := map[string]struct {
+		input    int64
+		expected interface{}
+	}{
+		"prize1":         {input: 100, expected: "prize1"},
+		"float result":   {input: 30, expected: 4.2},
+		"bool result":    {input: 10, expected: true},
+		"default result": {input: 45, expected: int64(0)},
+	}
+	for name, tc := range testCases {
+		t.Run(name, func(t *testing.T) {
+			result, err := ResolveTree(&tree, tc.input)
+			require.NoError(t, err)
+			assert.Equal(t, tc.expected, result)
+		})
+	}
+}
+
+func TestYAML_ListValues(t *testing.T) {
+	treeYAML := []byte(`
+name: plans
+root:
+  children:
+    - comparer: {type: in}
+      valueToCompare:
+        Type: list
+        Value: [1, 2, {Value: 3, Type: int64}]
+      result: {Value: basic, Type: string}
+    - default: true
+      result: {Value: premium, Type: string}
+`)
+	var tree Tree
+	require.NoError(t, yaml.Unmarshal(treeYAML, &tree))
+	assert.Equal(t, []interface{}{1, 2, int64(3)}, tree.Root.Children[0].ValueToCompare.Value)
+	encoded, err := yaml.Marshal(&tree)
+	require.NoError(t, err)
+	var decoded Tree
+	require.NoError(t, yaml.Unmarshal(encoded, &decoded))
+	assert.Equal(t, tree.Root.Children[0].ValueToCompare, decoded.Root.Children[0].ValueToCompare)
+	res, err := ResolveTree(&decoded, int64(3))
+	require.NoError(t, err)
+	assert.Equal(t, "basic", res)
+
+	b, err := json.Marshal(&tree)
+	require.NoError(t, err)
+	yamlValue, err := jsonToYAMLValue(b)
+	require.NoError(t, err)
+	root := yamlValue.(map[string]interface{})["nodes"].([]interface{})[0]
+	assert.Equal(t, -1, root.(map[string]interface{})["parentId"], "integers are normalized to int like json inputs")
+}
+
+func TestYAML_Errors(t *testing.T) {
+	ut := userTree()
+	err := yaml.Unmarshal([]byte(`
+name: orphans
+nodes:
+  - {id: 0, parentId: -1}
+  - {id: 2, parentId: 1, default: true, result: {Value: 1, Type: int}}
+`), ut)
+	assert.True(t, errors.Is(err, ErrOrphanNodes))
+	err = yaml.Unmarshal([]byte(`
+root:
+  children:
+    - {comparer: {type: eq}, result: {Value: 1, Type: int}}
+`), ut)
+	assert.EqualError(t, err, "invalid tree: node 1: missing value to compare")
+	err = yaml.Unmarshal([]byte(`
+root:
+  children:
+    - {default: true, result: {Value: 1, Type: int}, 1: 2}
+`), ut)
+	assert.Error(t, err)
+	assert.Equal(t, "userTree", ut.Name)
+}